@@ -0,0 +1,121 @@
+package copy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testLocaleKey struct{}
+
+func TestCopiers_CopyContext(t *testing.T) {
+	type item1 struct {
+		Name string
+	}
+
+	type item2 struct {
+		Name string
+	}
+
+	type testStruct1 struct {
+		Title string
+		Items []item1
+	}
+
+	type testStruct2 struct {
+		Title string
+		Items []*item2
+		Count int
+	}
+
+	c := New(
+		Converter(func(ctx context.Context, dst *string, src string) error {
+			if ctx.Value(testLocaleKey{}) == "upper" {
+				*dst = strings.ToUpper(src)
+			} else {
+				*dst = src
+			}
+			return nil
+		}),
+		Hook(func(dst *testStruct2, src *testStruct1) error {
+			dst.Count = len(src.Items)
+			return nil
+		}),
+	)
+
+	src := testStruct1{Title: "title", Items: []item1{{Name: "a"}, {Name: "b"}}}
+	dst := testStruct2{}
+
+	ctx := context.WithValue(context.Background(), testLocaleKey{}, "upper")
+	if err := c.CopyContext(ctx, &dst, &src); err != nil {
+		t.Fatalf("copy: %s", err)
+	}
+
+	expected := testStruct2{Title: "TITLE", Items: []*item2{{Name: "A"}, {Name: "B"}}, Count: 2}
+	equal(t, dst, expected)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.Get(&dst, &src).CopyContext(ctx, &dst, &src); !errors.Is(err, context.Canceled) {
+		t.Errorf("want «%s» got «%v»", context.Canceled, err)
+	}
+}
+
+func TestCopiers_CopyContextError(t *testing.T) {
+	type testStruct1 struct {
+		S string
+	}
+
+	type testStruct2 struct {
+		S int
+	}
+
+	errConvert := errors.New("convert")
+	c := New(Converter(func(dst *int, src string) error {
+		return errConvert
+	}))
+
+	if err := c.CopyContext(context.Background(), &testStruct2{}, &testStruct1{}); !errors.Is(err, errConvert) {
+		t.Errorf("want «%s» got «%v»", errConvert, err)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Copy must panic when a converter fails")
+			}
+		}()
+		c.Copy(&testStruct2{}, &testStruct1{})
+	}()
+}
+
+func TestCopiers_TypeHook(t *testing.T) {
+	type testStruct1 struct {
+		S string
+	}
+
+	type testStruct2 struct {
+		S    string
+		Type string
+	}
+
+	c := New(TypeHook(func(dst, src reflect.Type) HookFunc {
+		if _, ok := dst.FieldByName("Type"); !ok {
+			return nil
+		}
+		return func(ctx context.Context, dst, src reflect.Value) error {
+			dst.Elem().FieldByName("Type").SetString(src.Type().Elem().Name())
+			return nil
+		}
+	}))
+
+	dst := testStruct2{}
+	c.Copy(&dst, &testStruct1{S: "string"})
+
+	expected := testStruct2{S: "string", Type: "testStruct1"}
+	if dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+}
@@ -0,0 +1,235 @@
+package copy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gotidy/copy/structinfo"
+	"github.com/gotidy/copy/textfuncs"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// checkFunc checks that fn is a function of the form func([ctx context.Context,] dst *D, src S) error
+// and returns its value and the destination and source types.
+func checkFunc(fn interface{}) (f reflect.Value, dst, src reflect.Type) {
+	f = reflect.ValueOf(fn)
+	t := f.Type()
+	if t.Kind() != reflect.Func {
+		panic(fmt.Errorf("«%s» is not a function", t))
+	}
+
+	in := t.NumIn()
+	if in == 3 && t.In(0) == contextType {
+		in--
+	}
+
+	if in != 2 || t.NumOut() != 1 || t.Out(0) != errorType || t.In(t.NumIn()-2).Kind() != reflect.Ptr {
+		panic(fmt.Errorf("function «%s» must be of the form func([ctx context.Context,] dst *D, src S) error", t))
+	}
+
+	return f, t.In(t.NumIn() - 2).Elem(), t.In(t.NumIn() - 1)
+}
+
+// callFunc returns the copy function calling f. If srcAddr is true, the pointer to the source is passed to f.
+func callFunc(f reflect.Value, dst, src reflect.Type, srcAddr bool) copyFunc {
+	withContext := f.Type().NumIn() == 3
+
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		args := make([]reflect.Value, 0, 3)
+		if withContext {
+			args = append(args, reflect.ValueOf(&ctx).Elem())
+		}
+		args = append(args, addrOf(dstPtr, dst))
+		if srcAddr {
+			args = append(args, addrOf(srcPtr, src))
+		} else {
+			args = append(args, valueAt(srcPtr, src))
+		}
+
+		if err := f.Call(args)[0]; !err.IsNil() {
+			return err.Interface().(error)
+		}
+		return nil
+	}
+}
+
+// Converter registers the function converting values of a specific source type to values of a destination type.
+// The function must be of the form func([ctx context.Context,] dst *D, src S) error.
+// The context passed to CopyContext is passed to the function.
+//
+//   copy.New(copy.Converter(func(dst *time.Time, src string) (err error) {
+//       *dst, err = time.Parse(time.RFC3339, src)
+//       return err
+//   }))
+func Converter(fn interface{}) Option {
+	f, dst, src := checkFunc(fn)
+
+	return func(o *Options) {
+		if o.converters == nil {
+			o.converters = make(map[copierKey]reflect.Value)
+		}
+		o.converters[copierKey{Src: src, Dest: dst}] = f
+	}
+}
+
+// NamedConverter registers the function converting values of fields, which tag has the conv option with the name.
+// The function must be of the form func([ctx context.Context,] dst *D, src S) error, where D and S are types of the fields.
+//
+//   type UserDTO struct {
+//       Birthday string `copy:",conv=date"`
+//   }
+//   copy.New(copy.NamedConverter("date", func(dst *string, src time.Time) error {
+//       *dst = src.Format("2006-01-02")
+//       return nil
+//   }))
+func NamedConverter(name string, fn interface{}) Option {
+	f, _, _ := checkFunc(fn)
+
+	return func(o *Options) {
+		if o.namedConverters == nil {
+			o.namedConverters = make(map[string]reflect.Value)
+		}
+		o.namedConverters[name] = f
+	}
+}
+
+// Hook registers the function, that is called after a destination struct is filled from a source struct.
+// The function must be of the form func([ctx context.Context,] dst *D, src *S) error, where D and S are structs.
+// The context passed to CopyContext is passed to the function.
+//
+//   copy.New(copy.Hook(func(ctx context.Context, dst *UserDTO, src *User) error {
+//       dst.FullName = src.Name + " " + src.Surname
+//       return nil
+//   }))
+func Hook(fn interface{}) Option {
+	f, dst, src := checkFunc(fn)
+	if dst.Kind() != reflect.Struct || src.Kind() != reflect.Ptr || src.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("hook «%s» must be of the form func([ctx context.Context,] dst *D, src *S) error", f.Type()))
+	}
+	src = src.Elem()
+
+	return func(o *Options) {
+		if o.hooks == nil {
+			o.hooks = make(map[copierKey][]reflect.Value)
+		}
+		key := copierKey{Src: src, Dest: dst}
+		o.hooks[key] = append(o.hooks[key], f)
+	}
+}
+
+// HookFunc is called after a destination struct is filled from a source struct.
+// Dst and src are pointers to the structs.
+type HookFunc func(ctx context.Context, dst, src reflect.Value) error
+
+// TypeHook registers the function returning hooks for pairs of destination and source struct types,
+// if it returns nil then no hook is called for the pair. It is called once for each prepared pair on its first copy,
+// so it may get copiers. Returned hooks are called before hooks registered by Hook. It allows to extend copying
+// of whole families of types.
+//
+//   copy.New(copy.TypeHook(func(dst, src reflect.Type) copy.HookFunc {
+//       if !reflect.PtrTo(dst).Implements(validatorType) {
+//           return nil
+//       }
+//       return func(ctx context.Context, dst, src reflect.Value) error {
+//           return dst.Interface().(Validator).Validate()
+//       }
+//   }))
+func TypeHook(fn func(dst, src reflect.Type) HookFunc) Option {
+	return func(o *Options) {
+		o.typeHooks = append(o.typeHooks, fn)
+	}
+}
+
+// typeHookCopier returns the copier calling the hook returned by the type hook for the pair. The type hook is called
+// on the first copy rather than while the copier is prepared, so it does not run under construction of copiers.
+func typeHookCopier(typeHook func(dst, src reflect.Type) HookFunc, dst, src reflect.Type) fieldCopier {
+	var once sync.Once
+	var hook HookFunc
+
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		once.Do(func() { hook = typeHook(dst, src) })
+		if hook == nil {
+			return nil
+		}
+		return hook(ctx, addrOf(dstPtr, dst), addrOf(srcPtr, src))
+	}
+}
+
+// converter returns the copier calling the registered converter, if it is not found then nil is returned.
+func (c *Copiers) converter(dst, src reflect.Type) copyFunc {
+	f, ok := c.options.converters[copierKey{Src: src, Dest: dst}]
+	if !ok {
+		return nil
+	}
+
+	return callFunc(f, dst, src, false)
+}
+
+// namedConverter returns the copier calling the named converter set by the conv tag option of the fields,
+// if the option is not set then nil is returned. It panics if the converter is not registered or does not fit the fields.
+func (c *Copiers) namedConverter(dst, src structinfo.Field) copyFunc {
+	name := dst.Options.Conv
+	if name == "" {
+		name = src.Options.Conv
+	} else if src.Options.Conv != "" && src.Options.Conv != name {
+		panic(fmt.Errorf("field «%s» has converters «%s» and «%s»", dst.Name, src.Options.Conv, name))
+	}
+	if name == "" {
+		return nil
+	}
+
+	f, ok := c.options.namedConverters[name]
+	if !ok {
+		panic(fmt.Errorf("converter «%s» of field «%s» is not registered", name, dst.Name))
+	}
+
+	_, dstType, srcType := checkFunc(f.Interface())
+	if dstType != dst.Type || srcType != src.Type {
+		panic(fmt.Errorf("converter «%s» of type «%s» can not copy field «%s» of type «%s» to field «%s» of type «%s»",
+			name, f.Type(), src.Name, src.Type, dst.Name, dst.Type))
+	}
+
+	return callFunc(f, dst.Type, src.Type, false)
+}
+
+// hookCopier returns the copier calling the hook.
+func hookCopier(f reflect.Value, dst, src reflect.Type) fieldCopier {
+	return callFunc(f, dst, src, true)
+}
+
+// textCopier returns the copier converting strings from and to values of other types by the textfuncs package,
+// so copying shares the rules with url values, headers and environment variables.
+// If the types are not supported then nil is returned.
+func textCopier(dst, src reflect.Type) copyFunc {
+	switch {
+	case src.Kind() == reflect.String && dst.Kind() != reflect.String:
+		parse := textfuncs.GetParse(dst)
+		if parse == nil {
+			return nil
+		}
+		return func(_ context.Context, dstPtr, srcPtr pointer) error {
+			return parse(valueAt(dstPtr, dst), valueAt(srcPtr, src).String())
+		}
+	case dst.Kind() == reflect.String && src.Kind() != reflect.String:
+		format := textfuncs.GetFormat(src)
+		if format == nil {
+			return nil
+		}
+		return func(_ context.Context, dstPtr, srcPtr pointer) error {
+			s, err := format(valueAt(srcPtr, src))
+			if err != nil {
+				return err
+			}
+			valueAt(dstPtr, dst).SetString(s)
+			return nil
+		}
+	}
+
+	return nil
+}
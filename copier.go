@@ -2,13 +2,14 @@
 package copy
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
-	"unsafe"
 
-	"github.com/gotidy/copy/funcs"
-	"github.com/gotidy/copy/internal/cache"
+	"github.com/gotidy/copy/internal/lru"
+	"github.com/gotidy/copy/structinfo"
 )
 
 const defaultTagName = "copy"
@@ -22,6 +23,17 @@ type copierKey struct {
 type Options struct {
 	Tag  string
 	Skip bool
+
+	tags            []string
+	enums           []enum
+	policies        map[reflect.Kind]Policy
+	converters      map[copierKey]reflect.Value
+	namedConverters map[string]reflect.Value
+	hooks           map[copierKey][]reflect.Value
+	typeHooks       []func(dst, src reflect.Type) HookFunc
+	ignore          []func(name string) bool
+	masks           map[string]MaskFunc
+	cacheSize       int
 }
 
 // Option changes default Copiers parameters.
@@ -31,6 +43,22 @@ type Option func(c *Options)
 func Tag(tag string) Option {
 	return func(o *Options) {
 		o.Tag = tag
+		o.tags = nil
+	}
+}
+
+// Tags sets tag names consulted in priority order. The first tag is parsed with all options, whatever its name,
+// the others, such as json or db, provide only names of fields: "-" omits the field, and options,
+// such as omitempty, are ignored. A tag without a name falls back to the next one.
+//
+//   copy.New(copy.Tags("copy", "json", "db"))
+func Tags(tags ...string) Option {
+	return func(o *Options) {
+		o.Tag = ""
+		if len(tags) > 0 {
+			o.Tag = tags[0]
+		}
+		o.tags = tags
 	}
 }
 
@@ -41,13 +69,77 @@ func Skip() Option {
 	}
 }
 
+// CacheSize limits the number of cached copiers and struct infos, the least recently used ones are evicted.
+// It is meant for copiers of dynamic types, that are created at run time. By default caches are not limited
+// and struct infos are shared by copiers with the same tags.
+//
+//   copy.New(copy.CacheSize(1000))
+func CacheSize(n int) Option {
+	return func(o *Options) {
+		o.cacheSize = n
+	}
+}
+
+// Ignore skips fields, which names match the predicate. Names are names the fields are matched by.
+// Ignored fields are neither copied nor compared, read or filled by maps, url values, headers and environment variables.
+//
+//   copy.New(copy.Ignore(func(name string) bool { return strings.HasPrefix(name, "XXX_") }))
+func Ignore(match func(name string) bool) Option {
+	return func(o *Options) {
+		o.ignore = append(o.ignore, match)
+	}
+}
+
+// ignored reports whether the field is skipped by Ignore options.
+func (c *Copiers) ignored(name string) bool {
+	for _, match := range c.options.ignore {
+		if match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy defines how fields of a reference kind are copied.
+type Policy int
+
+const (
+	// PolicyShare copies the reference, so the destination and the source share the referenced value.
+	PolicyShare Policy = iota
+	// PolicySkip skips fields.
+	PolicySkip
+	// PolicyError causes panic when a copier is prepared.
+	PolicyError
+)
+
+// KindPolicy sets the policy of copying fields of the kind. Only reflect.Chan and reflect.Func are supported,
+// by default they are shared.
+//
+//   copy.New(copy.KindPolicy(reflect.Func, copy.PolicySkip), copy.KindPolicy(reflect.Chan, copy.PolicyError))
+func KindPolicy(kind reflect.Kind, policy Policy) Option {
+	if kind != reflect.Chan && kind != reflect.Func {
+		panic(fmt.Errorf("policy of kind «%s» is not supported", kind))
+	}
+
+	return func(o *Options) {
+		if o.policies == nil {
+			o.policies = make(map[reflect.Kind]Policy)
+		}
+		o.policies[kind] = policy
+	}
+}
+
 // Copiers is a structs copier.
 type Copiers struct {
-	cache   *cache.Cache
+	cache   *structinfo.Cache
+	shared  bool // The cache is shared with other copiers.
 	options Options
 
-	mu      sync.RWMutex
-	copiers map[copierKey]Copier
+	copiers    *lru.Cache // Prepared copiers by copierKey.
+	converters *lru.Cache // Copiers of values of any types by copierKey, used by Diff, Equal and FromMap.
+
+	mu       sync.RWMutex
+	mappings map[copierKey]*mapping
 }
 
 // New create new Copier.
@@ -58,98 +150,371 @@ func New(options ...Option) *Copiers {
 		option(&opts)
 	}
 
-	return &Copiers{cache: cache.New(opts.Tag), options: opts, copiers: make(map[copierKey]Copier)}
+	tags := opts.tags
+	if tags == nil {
+		tags = []string{opts.Tag}
+	}
+
+	if opts.cacheSize > 0 {
+		return &Copiers{
+			cache:      structinfo.NewLRU(opts.cacheSize, tags...),
+			options:    opts,
+			copiers:    lru.New(opts.cacheSize),
+			converters: lru.New(opts.cacheSize),
+		}
+	}
+
+	return &Copiers{cache: structinfo.Shared(tags...), shared: true, options: opts, copiers: lru.New(0), converters: lru.New(0)}
+}
+
+// Stats is statistics of caches of Copiers.
+type Stats struct {
+	Copiers structinfo.CacheStats // Prepared copiers.
+	Structs structinfo.CacheStats // Struct infos, they are not counted if the cache is shared with other copiers.
+}
+
+// Stats returns statistics of caches of the copiers. Struct infos are shared by copiers with the same tags,
+// unless CacheSize is set, statistics of the shared cache are not returned.
+func (c *Copiers) Stats() Stats {
+	stats := Stats{Copiers: structinfo.CacheStats(c.copiers.Stats())}
+	if !c.shared {
+		stats.Structs = c.cache.Stats()
+	}
+	return stats
+}
+
+// Forget removes the copier for a specific destination and source, and infos of their structs,
+// unless they are shared with other copiers. They are prepared again on demand. Copiers of nested structs are kept.
+func (c *Copiers) Forget(dst, src interface{}) {
+	srcType := reflect.Indirect(reflect.ValueOf(src)).Type()
+	dstType := reflect.Indirect(reflect.ValueOf(dst)).Type()
+
+	c.copiers.Remove(copierKey{Src: srcType, Dest: dstType})
+	c.converters.Remove(copierKey{Src: srcType, Dest: dstType})
+	if !c.shared {
+		c.cache.Forget(srcType)
+		c.cache.Forget(dstType)
+	}
+}
+
+// Reset removes all prepared copiers and struct infos, unless they are shared with other copiers,
+// and resets statistics. Mappings are kept.
+func (c *Copiers) Reset() {
+	c.copiers.Reset()
+	c.converters.Reset()
+	if !c.shared {
+		c.cache.Reset()
+	}
 }
 
-type fieldCopier = func(dst, src unsafe.Pointer)
+// copyFunc copies a value. The context is passed to registered converters and hooks.
+type copyFunc = func(ctx context.Context, dst, src pointer) error
 
-func (c *Copiers) fieldCopier(dst, src cache.Field) fieldCopier {
-	dstOffset := dst.Offset
-	srcOffset := src.Offset
-	copier := funcs.Get(dst.Type, src.Type)
-	if copier != nil {
-		return func(dstPtr, srcPtr unsafe.Pointer) {
-			copier(unsafe.Pointer(uintptr(dstPtr)+dstOffset), unsafe.Pointer(uintptr(srcPtr)+srcOffset))
+type fieldCopier = copyFunc
+
+func (c *Copiers) fieldCopier(dst, src structinfo.Field, pending map[copierKey]*Copier) fieldCopier {
+	for _, kind := range []reflect.Kind{src.Type.Kind(), dst.Type.Kind()} {
+		switch c.options.policies[kind] {
+		case PolicySkip:
+			return nil
+		case PolicyError:
+			panic(fmt.Errorf(`field «%s» of type «%s» can not be copied to field «%s» of type «%s»: %s fields are forbidden`,
+				src.Name, src.Type.String(), dst.Name, dst.Type.String(), kind))
 		}
 	}
 
-	// same type -> same type
-	if src.Type == dst.Type {
-		size := int(src.Type.Size())
+	// Fields of nil embedded pointers of the source are not copied,
+	// nil embedded pointers of the destination are allocated.
+	copier := c.namedConverter(dst, src)
+	if copier == nil {
+		// Values copied by copy functions are copied without an intermediate function call.
+		if c.customCopier(dst.Type, src.Type) == nil {
+			if copier := valueCopier(dst.Type, src.Type); copier != nil {
+				if len(dst.Path) == 0 && len(src.Path) == 0 {
+					return func(_ context.Context, dstPtr, srcPtr pointer) error {
+						copier(fieldAt(dstPtr, dst), fieldAt(srcPtr, src))
+						return nil
+					}
+				}
+				return func(_ context.Context, dstPtr, srcPtr pointer) error {
+					if srcPtr, ok := fieldPtr(srcPtr, src); ok {
+						copier(fieldPtrAlloc(dstPtr, dst), srcPtr)
+					}
+					return nil
+				}
+			}
+		}
 
-		return func(dstPtr, srcPtr unsafe.Pointer) {
-			// More safe and independent from internal structs
-			// src := reflect.NewAt(src.Type, unsafe.Pointer(uintptr(srcPtr)+src.Offset)).Elem()
-			// dst := reflect.NewAt(dst.Type, unsafe.Pointer(uintptr(dstPtr)+dst.Offset)).Elem()
-			// dst.Set(src)
-			memcopy(unsafe.Pointer(uintptr(dstPtr)+dst.Offset), unsafe.Pointer(uintptr(srcPtr)+src.Offset), size)
+		copier = c.typeCopier(dst.Type, src.Type, pending)
+	}
+	if copier == nil {
+		if !c.options.Skip {
+			panic(fmt.Errorf(`field «%s» of type «%s» is not assignable to field «%s» of type «%s»`, src.Name, src.Type.String(), dst.Name, dst.Type.String()))
+		}
+
+		return nil
+	}
+
+	if len(dst.Path) == 0 && len(src.Path) == 0 {
+		return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+			return copier(ctx, fieldAt(dstPtr, dst), fieldAt(srcPtr, src))
+		}
+	}
+
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		srcPtr, ok := fieldPtr(srcPtr, src)
+		if !ok {
+			return nil
+		}
+		return copier(ctx, fieldPtrAlloc(dstPtr, dst), srcPtr)
+	}
+}
+
+// plainCopier returns the copier of the field value, if it is copied without converters, policies, options
+// and conditions, that need the context or may fail. Otherwise nil is returned.
+func (c *Copiers) plainCopier(dst, src structinfo.Field, m *mapping) func(dst, src pointer) {
+	if len(dst.Path) > 0 || len(src.Path) > 0 || dst.Anonymous && src.Anonymous &&
+		(dst.Type.Kind() == reflect.Ptr || src.Type.Kind() == reflect.Ptr) {
+		return nil
+	}
+	if src.Options.OmitEmpty || dst.Options.OmitEmpty || len(src.Policy.Roles) > 0 || len(dst.Policy.Roles) > 0 ||
+		m != nil && len(m.conditions[src.Name]) > 0 || fieldDefault(dst, m) != nil {
+		return nil
+	}
+	for _, kind := range []reflect.Kind{src.Type.Kind(), dst.Type.Kind()} {
+		if c.options.policies[kind] != PolicyShare {
+			return nil
+		}
+	}
+	if c.fieldMask(src, dst.Name, dst.Type) != nil || c.fieldMask(dst, dst.Name, dst.Type) != nil ||
+		c.namedConverter(dst, src) != nil || c.customCopier(dst.Type, src.Type) != nil {
+		return nil
+	}
+
+	copier := valueCopier(dst.Type, src.Type)
+	if copier == nil {
+		return nil
+	}
+	return func(dstPtr, srcPtr pointer) {
+		copier(fieldAt(dstPtr, dst), fieldAt(srcPtr, src))
+	}
+}
+
+// embeddedCopier returns the copier of the structs embedded into both structs, that are copied whole.
+// Embedded pointers are not shared, the destination struct is allocated.
+func (c *Copiers) embeddedCopier(dst, src structinfo.Field, pending map[copierKey]*Copier) fieldCopier {
+	if dst.Type.Kind() != reflect.Ptr && src.Type.Kind() != reflect.Ptr ||
+		c.namedConverter(dst, src) != nil || c.customCopier(dst.Type, src.Type) != nil {
+		return c.fieldCopier(dst, src, pending)
+	}
+
+	dstType, srcType := indirectType(dst.Type), indirectType(src.Type)
+	var copier copyFunc
+	if value := valueCopier(dstType, srcType); value != nil {
+		// Structs of the same type are copied with unexported fields.
+		copier = func(_ context.Context, dstPtr, srcPtr pointer) error {
+			value(dstPtr, srcPtr)
+			return nil
+		}
+	} else {
+		copier = c.prepare(dstType, srcType, pending).copy
+	}
+
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		srcPtr, ok := fieldPtr(srcPtr, src)
+		if ok && src.Type.Kind() == reflect.Ptr {
+			srcPtr, ok = deref(srcPtr)
+		}
+		if !ok {
+			return nil
+		}
+		dstPtr = fieldPtrAlloc(dstPtr, dst)
+		if dst.Type.Kind() == reflect.Ptr {
+			dstPtr = derefAlloc(dstPtr, dstType)
+		}
+		return copier(ctx, dstPtr, srcPtr)
+	}
+}
+
+// omitEmptyCopier returns the copier, that does not copy zero source values.
+func omitEmptyCopier(copier fieldCopier, src structinfo.Field) fieldCopier {
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		if p, ok := fieldPtr(srcPtr, src); !ok || valueAt(p, src.Type).IsZero() {
+			return nil
+		}
+		return copier(ctx, dstPtr, srcPtr)
+	}
+}
+
+// fieldValue returns the field of the struct value and false if an embedded pointer on the way to it is nil.
+func fieldValue(v reflect.Value, f structinfo.Field) (reflect.Value, bool) {
+	for _, i := range f.Index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+// fieldValueAlloc returns the field of the addressable struct value, nil embedded pointers on the way to it are allocated.
+func fieldValueAlloc(v reflect.Value, f structinfo.Field) reflect.Value {
+	for _, i := range f.Index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// typeCopier returns the copier of values of the specific types, if the types are not assignable then nil is returned.
+func (c *Copiers) typeCopier(dst, src reflect.Type, pending map[copierKey]*Copier) copyFunc {
+	if copier := c.customCopier(dst, src); copier != nil {
+		return copier
+	}
+
+	if copier := valueCopier(dst, src); copier != nil {
+		return func(_ context.Context, dstPtr, srcPtr pointer) error {
+			copier(dstPtr, srcPtr)
+			return nil
+		}
+	}
+
+	// []T1 -> []T2
+	if src.Kind() == reflect.Slice && dst.Kind() == reflect.Slice {
+		if copier := c.typeCopier(dst.Elem(), src.Elem(), pending); copier != nil {
+			return sliceCopier(dst, src, copier)
 		}
 	}
 
 	// struct -> struct
-	if src.Type.Kind() == reflect.Struct && dst.Type.Kind() == reflect.Struct {
-		copier := c.get(dst.Type, src.Type)
+	if src.Kind() == reflect.Struct && dst.Kind() == reflect.Struct {
+		copier := c.prepare(dst, src, pending)
 
-		return func(dstPtr, srcPtr unsafe.Pointer) {
-			copier.copy(unsafe.Pointer(uintptr(dstPtr)+dst.Offset), unsafe.Pointer(uintptr(srcPtr)+src.Offset))
+		return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+			return copier.copy(ctx, dstPtr, srcPtr)
 		}
 	}
 
 	// *struct -> struct
-	if src.Type.Kind() == reflect.Ptr && src.Type.Elem().Kind() == reflect.Struct && dst.Type.Kind() == reflect.Struct {
-		copier := c.get(dst.Type, src.Type.Elem())
+	if src.Kind() == reflect.Ptr && src.Elem().Kind() == reflect.Struct && dst.Kind() == reflect.Struct {
+		copier := c.prepare(dst, src.Elem(), pending)
 
-		return func(dstPtr, srcPtr unsafe.Pointer) {
-			srcFieldPtr := (**struct{})(unsafe.Pointer(uintptr(srcPtr) + src.Offset))
-			if *srcFieldPtr == nil {
-				return
+		return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+			srcPtr, ok := deref(srcPtr)
+			if !ok {
+				return nil
 			}
-			copier.copy(unsafe.Pointer(uintptr(dstPtr)+dst.Offset), unsafe.Pointer(*srcFieldPtr))
+			return copier.copy(ctx, dstPtr, srcPtr)
 		}
 	}
 
 	// struct -> *struct
-	if src.Type.Kind() == reflect.Struct && dst.Type.Kind() == reflect.Ptr && dst.Type.Elem().Kind() == reflect.Struct {
-		copier := c.get(dst.Type.Elem(), src.Type)
+	if src.Kind() == reflect.Struct && dst.Kind() == reflect.Ptr && dst.Elem().Kind() == reflect.Struct {
+		copier := c.prepare(dst.Elem(), src, pending)
 
-		dstSize := int(dst.Type.Elem().Size())
+		dstType := dst.Elem()
 
-		return func(dstPtr, srcPtr unsafe.Pointer) {
-			dstFieldPtr := (**struct{})(unsafe.Pointer(uintptr(dstPtr) + dst.Offset))
-			if *dstFieldPtr == nil {
-				*dstFieldPtr = (*struct{})(alloc(dstSize))
-			}
-
-			copier.copy(unsafe.Pointer(*dstFieldPtr), unsafe.Pointer(uintptr(srcPtr)+src.Offset))
+		return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+			return copier.copy(ctx, derefAlloc(dstPtr, dstType), srcPtr)
 		}
 	}
 
 	// *struct -> *struct
-	if src.Type.Kind() == reflect.Ptr && src.Type.Elem().Kind() == reflect.Struct &&
-		dst.Type.Kind() == reflect.Ptr && dst.Type.Elem().Kind() == reflect.Struct {
-		copier := c.get(dst.Type.Elem(), src.Type.Elem())
+	if src.Kind() == reflect.Ptr && src.Elem().Kind() == reflect.Struct &&
+		dst.Kind() == reflect.Ptr && dst.Elem().Kind() == reflect.Struct {
+		copier := c.prepare(dst.Elem(), src.Elem(), pending)
 
-		dstSize := int(dst.Type.Elem().Size())
+		dstType := dst.Elem()
 
-		return func(dstPtr, srcPtr unsafe.Pointer) {
-			srcFieldPtr := (**struct{})(unsafe.Pointer(uintptr(srcPtr) + src.Offset))
-			if *srcFieldPtr == nil {
-				return
+		return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+			srcPtr, ok := deref(srcPtr)
+			if !ok {
+				return nil
 			}
-			dstFieldPtr := (**struct{})(unsafe.Pointer(uintptr(dstPtr) + dst.Offset))
-			if *dstFieldPtr == nil {
-				*dstFieldPtr = (*struct{})(alloc(dstSize))
+			if !copier.recursive {
+				return copier.copy(ctx, derefAlloc(dstPtr, dstType), srcPtr)
 			}
 
-			copier.copy(unsafe.Pointer(*dstFieldPtr), unsafe.Pointer(*srcFieldPtr))
+			// Values of recursive types may be cyclic, a source struct is copied once and
+			// all pointers to it are pointed to the same destination struct.
+			visits, ok := ctx.Value(visitsKey{}).(map[visit]pointer)
+			if !ok {
+				visits = make(map[visit]pointer)
+				ctx = context.WithValue(ctx, visitsKey{}, visits)
+			}
+			v := visit{src: addrKey(srcPtr), dst: dstType}
+			if target, ok := visits[v]; ok {
+				setPointer(dstPtr, target)
+				return nil
+			}
+			target := derefAlloc(dstPtr, dstType)
+			visits[v] = target
+			return copier.copy(ctx, target, srcPtr)
 		}
 	}
 
-	if !c.options.Skip {
-		panic(fmt.Errorf(`field «%s» of type «%s» is not assignable to field «%s» of type «%s»`, src.Name, src.Type.String(), dst.Name, dst.Type.String()))
+	// string -> T, T -> string
+	return textCopier(dst, src)
+}
+
+// visitsKey is the context key of structs copied by the current call, it is set only for recursive types.
+type visitsKey struct{}
+
+// visit is a source struct copied to a destination type.
+type visit struct {
+	src uintptr
+	dst reflect.Type
+}
+
+// customCopier returns the copier of registered converters and enums, if it is not found then nil is returned.
+func (c *Copiers) customCopier(dst, src reflect.Type) copyFunc {
+	if copier := c.converter(dst, src); copier != nil {
+		return copier
 	}
 
-	return nil
+	return c.enumCopier(dst, src)
+}
+
+// sliceCopier returns the copier of slices, which copies elements one by one.
+// The context cancellation is checked between elements.
+func sliceCopier(dst, src reflect.Type, elem copyFunc) copyFunc {
+	dstElem := dst.Elem()
+	srcElem := src.Elem()
+
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		if sliceIsNil(srcPtr) {
+			setZero(dstPtr, dst)
+			return nil
+		}
+
+		n := sliceLen(srcPtr)
+		makeSlice(dstPtr, dst, n)
+
+		done := ctx.Done()
+		for i := 0; i < n; i++ {
+			if done != nil {
+				select {
+				case <-done:
+					return ctx.Err()
+				default:
+				}
+			}
+
+			if err := elem(ctx, sliceIndex(dstPtr, dstElem, i), sliceIndex(srcPtr, srcElem, i)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
 }
 
 // Prepare caches structures of src and dst. Dst and src each must be a pointer to struct.
@@ -162,12 +527,35 @@ func (c *Copiers) Prepare(dst, src interface{}) {
 }
 
 // Copy copies the contents of src into dst. Dst and src each must be a pointer to struct.
+// It panics if copying fails, e.g. a registered converter returns an error.
 func (c *Copiers) Copy(dst, src interface{}) {
+	copier, dstPtr, srcPtr := c.pointers(dst, src)
+	if len(copier.copiers) == 0 {
+		copier.copyValues(dstPtr, srcPtr)
+		return
+	}
+
+	if err := copier.copyRoot(context.Background(), dstPtr, srcPtr); err != nil {
+		panic(err)
+	}
+}
+
+// CopyContext copies the contents of src into dst. Dst and src each must be a pointer to struct.
+// The context is passed to registered converters and hooks, the cancellation is checked
+// between elements of copied slices.
+func (c *Copiers) CopyContext(ctx context.Context, dst, src interface{}) error {
+	copier, dstPtr, srcPtr := c.pointers(dst, src)
+
+	return copier.copyRoot(ctx, dstPtr, srcPtr)
+}
+
+// pointers returns the copier and pointers to the structs. Dst and src each must be a pointer to struct.
+func (c *Copiers) pointers(dst, src interface{}) (*Copier, pointer, pointer) {
 	srcValue := reflect.ValueOf(src)
 	if srcValue.Kind() != reflect.Ptr {
 		panic("source must be pointer to struct")
 	}
-	srcPtr := unsafe.Pointer(srcValue.Pointer())
+	srcPtr := valuePointer(srcValue)
 	srcValue = srcValue.Elem()
 	if srcValue.Kind() != reflect.Struct {
 		panic("source must be pointer to struct")
@@ -177,43 +565,264 @@ func (c *Copiers) Copy(dst, src interface{}) {
 	if dstValue.Kind() != reflect.Ptr {
 		panic("destination must be pointer to struct")
 	}
-	dstPtr := unsafe.Pointer(dstValue.Pointer())
+	dstPtr := valuePointer(dstValue)
 	dstValue = dstValue.Elem()
 	if dstValue.Kind() != reflect.Struct {
 		panic("destination must be pointer to struct")
 	}
 
-	copier := c.get(dstValue.Type(), srcValue.Type())
+	return c.get(dstValue.Type(), srcValue.Type()), dstPtr, srcPtr
+}
+
+func (c *Copiers) get(dst, src reflect.Type) *Copier {
+	key := copierKey{Src: src, Dest: dst}
+	if copier, ok := c.copiers.Lookup(key); ok {
+		return copier.(*Copier)
+	}
+
+	// Concurrent first requests of the pair wait for the one preparing the copier, other pairs are prepared
+	// in parallel. Copiers of nested structs are prepared by the same call and published together with
+	// the requested one, so incomplete copiers of recursive types are never visible to other callers.
+	return c.copiers.Do(key, func() interface{} {
+		pending := make(map[copierKey]*Copier)
+		copier := c.newCopier(dst, src, pending)
+		c.publish(pending)
+		return copier
+	}).(*Copier)
+}
 
-	copier.copy(dstPtr, srcPtr)
+// publish stores prepared copiers.
+func (c *Copiers) publish(pending map[copierKey]*Copier) {
+	for key, copier := range pending {
+		c.copiers.Add(key, copier)
+	}
 }
 
-func (c *Copiers) get(dst, src reflect.Type) Copier {
-	c.mu.RLock()
-	copier, ok := c.copiers[copierKey{Src: src, Dest: dst}]
-	c.mu.RUnlock()
-	if ok {
+// prepare returns the copier for a specific destination and source.
+// Copiers under construction are taken from pending, that allows to copy recursive types.
+func (c *Copiers) prepare(dst, src reflect.Type, pending map[copierKey]*Copier) *Copier {
+	key := copierKey{Src: src, Dest: dst}
+
+	if copier, ok := pending[key]; ok {
+		// The copier references itself, if it is still under construction.
+		copier.recursive = copier.recursive || !copier.ready
 		return copier
 	}
 
+	if copier, ok := c.copiers.Get(key); ok {
+		return copier.(*Copier)
+	}
+
+	return c.newCopier(dst, src, pending)
+}
+
+// newCopier creates the copier for a specific destination and source, nested copiers are prepared with pending.
+func (c *Copiers) newCopier(dst, src reflect.Type, pending map[copierKey]*Copier) *Copier {
+	key := copierKey{Src: src, Dest: dst}
+	copier := &Copier{owner: c, dst: dst, src: src}
+	pending[key] = copier
+
 	srcStruct := c.cache.GetByType(src)
 	dstStruct := c.cache.GetByType(dst)
+	m := c.mapping(dst, src)
 
+	matched := make(map[string]bool, dstStruct.NumField())
+	covered := make(map[string]bool) // Ambiguous fields copied with the structs they are promoted from.
+	var whole [][2]structinfo.Field  // Embedded destination and source structs copied whole.
 	for i := 0; i < srcStruct.NumField(); i++ {
 		srcField := srcStruct.Field(i)
+		// Write-only fields are not read as a source.
+		if c.ignored(srcField.Name) || srcField.Options.WriteOnly {
+			continue
+		}
+		if !srcField.Anonymous {
+			c.checkAmbiguous(dstStruct, srcField.Name, dst)
+		}
 		if dstField, ok := dstStruct.FieldByName(srcField.Name); ok {
-			if f := c.fieldCopier(dstField, srcField); f != nil {
+			matched[dstField.Name] = true
+
+			// Read-only fields are not filled as a destination.
+			if dstField.Options.ReadOnly {
+				continue
+			}
+
+			// Structs embedded into both structs are copied whole, so fields hidden by the containing structs
+			// and unexported fields are kept. They are copied field by field, if conditions, policies or options
+			// target their promoted fields.
+			if wholeEmbedded(whole, dstField, srcField) {
+				continue
+			}
+			if srcField.Anonymous && dstField.Anonymous {
+				if c.splitEmbedded(dstStruct, srcStruct, dstField, srcField, m) {
+					continue
+				}
+				whole = append(whole, [2]structinfo.Field{dstField, srcField})
+				for name, fields := range srcStruct.Ambiguous {
+					for _, f := range fields {
+						covered[name] = covered[name] || promoted(f, srcField)
+					}
+				}
+			}
+
+			// Plain values are copied without the context.
+			if f := c.plainCopier(dstField, srcField, m); f != nil {
+				copier.values = append(copier.values, f)
+				continue
+			}
+
+			fieldCopier := c.fieldCopier
+			if srcField.Anonymous && dstField.Anonymous {
+				fieldCopier = c.embeddedCopier
+			}
+			if f := fieldCopier(dstField, srcField, pending); f != nil {
+				f = c.policyCopier(f, dstField, srcField)
+				if srcField.Options.OmitEmpty || dstField.Options.OmitEmpty {
+					f = omitEmptyCopier(f, srcField)
+				}
+				if set := fieldDefault(dstField, m); set != nil {
+					f = defaultValueCopier(f, dstField, srcField, set)
+				}
+				if m != nil && len(m.conditions[srcField.Name]) > 0 {
+					f = conditionCopier(f, src, m.conditions[srcField.Name])
+				}
 				copier.copiers = append(copier.copiers, f)
 			}
 		}
 	}
-	c.mu.Lock()
-	c.copiers[copierKey{Src: src, Dest: dst}] = copier
-	c.mu.Unlock()
+
+	// Fields ambiguous on both sides are lost, unless they are copied with the structs they are promoted from.
+	for name := range srcStruct.Ambiguous {
+		if _, ok := dstStruct.Ambiguous[name]; ok && !covered[name] {
+			c.checkAmbiguous(srcStruct, name, src)
+		}
+	}
+
+	// Destination fields missing from the source are set to default values.
+	for i := 0; i < dstStruct.NumField(); i++ {
+		dstField := dstStruct.Field(i)
+		if matched[dstField.Name] || c.ignored(dstField.Name) || dstField.Options.ReadOnly {
+			continue
+		}
+		if !dstField.Anonymous {
+			c.checkAmbiguous(srcStruct, dstField.Name, src)
+		}
+		if set := fieldDefault(dstField, m); set != nil {
+			copier.copiers = append(copier.copiers, defaultFieldCopier(dstField, set))
+		} else if dstField.Options.Required {
+			panic(fmt.Errorf("required field «%s» of «%s» is missing from «%s»", dstField.Name, dst, src))
+		}
+	}
+
+	for _, typeHook := range c.options.typeHooks {
+		copier.copiers = append(copier.copiers, typeHookCopier(typeHook, dst, src))
+	}
+
+	for _, hook := range c.options.hooks[key] {
+		copier.copiers = append(copier.copiers, hookCopier(hook, dst, src))
+	}
+
+	copier.ready = true
 
 	return copier
 }
 
+// splitEmbedded reports whether the structs embedded into both structs are copied field by field,
+// that is when conditions, policies or options target their promoted fields.
+// Embedded structs promoting ambiguous fields are copied whole.
+func (c *Copiers) splitEmbedded(dstStruct, srcStruct structinfo.Struct, dst, src structinfo.Field, m *mapping) bool {
+	if promotesAmbiguous(srcStruct, src) || promotesAmbiguous(dstStruct, dst) {
+		return false
+	}
+
+	for _, f := range srcStruct.Fields {
+		if !promoted(f, src) {
+			continue
+		}
+		if c.targeted(f) || m != nil && len(m.conditions[f.Name]) > 0 {
+			return true
+		}
+		if d, ok := dstStruct.FieldByName(f.Name); ok && c.customCopier(d.Type, f.Type) != nil {
+			return true
+		}
+	}
+	for _, f := range dstStruct.Fields {
+		if !promoted(f, dst) {
+			continue
+		}
+		if _, ok := m.defaultValue(f.Name); ok || c.targeted(f) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// targeted reports whether options, policies or tags change copying of the field.
+func (c *Copiers) targeted(f structinfo.Field) bool {
+	o := f.Options
+	return c.ignored(f.Name) || c.options.policies[f.Type.Kind()] != PolicyShare ||
+		o.OmitEmpty || o.ReadOnly || o.WriteOnly || o.Conv != "" || f.Default != "" ||
+		f.Policy.Mask != "" || len(f.Policy.Roles) > 0
+}
+
+// wholeEmbedded reports whether the fields are promoted from the embedded structs, that are copied whole.
+func wholeEmbedded(whole [][2]structinfo.Field, dst, src structinfo.Field) bool {
+	for _, w := range whole {
+		if promoted(dst, w[0]) && promoted(src, w[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// promoted reports whether the field is promoted from the embedded field.
+func promoted(f, embedded structinfo.Field) bool {
+	if len(f.Index) <= len(embedded.Index) {
+		return false
+	}
+	for i, index := range embedded.Index {
+		if f.Index[i] != index {
+			return false
+		}
+	}
+	return true
+}
+
+// promotesAmbiguous reports whether the struct has ambiguous fields promoted from the embedded field.
+func promotesAmbiguous(s structinfo.Struct, embedded structinfo.Field) bool {
+	for _, fields := range s.Ambiguous {
+		for _, f := range fields {
+			if promoted(f, embedded) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkAmbiguous panics if the name of the struct is ambiguous, so the field matching the name can not be copied.
+func (c *Copiers) checkAmbiguous(s structinfo.Struct, name string, t reflect.Type) {
+	fields, ok := s.Ambiguous[name]
+	if !ok || c.options.Skip {
+		return
+	}
+
+	parents := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parents = append(parents, "«"+f.ParentName+"»")
+	}
+	panic(fmt.Errorf("field «%s» of «%s» is ambiguous, it is promoted from %s", name, t, strings.Join(parents, ", ")))
+}
+
+// Struct returns the metadata of the struct as the copiers see it. I must be a struct or a pointer to struct.
+//
+//   for _, f := range c.Struct(&User{}).Fields {
+//       fmt.Println(f.Name)
+//   }
+func (c *Copiers) Struct(i interface{}) structinfo.Struct {
+	return c.cache.Get(i)
+}
+
 // Get Copier for a specific destination and source.
 func (c *Copiers) Get(dst, src interface{}) Copier {
 	srcValue := reflect.Indirect(reflect.ValueOf(src))
@@ -226,37 +835,67 @@ func (c *Copiers) Get(dst, src interface{}) Copier {
 		panic("destination must be struct")
 	}
 
-	return c.get(dstValue.Type(), srcValue.Type())
+	return *c.get(dstValue.Type(), srcValue.Type())
 }
 
 // Copier fills a destination from source.
 type Copier struct {
-	copiers []fieldCopier
+	owner     *Copiers
+	dst, src  reflect.Type
+	values    []func(dst, src pointer) // Copiers of plain values, they need no context and do not fail.
+	copiers   []fieldCopier
+	ready     bool // The copier is prepared.
+	recursive bool // Values of the types may reference each other, so copied structs are tracked.
 }
 
 // Copy copies the contents of src into dst. Dst and src each must be a pointer to struct.
+// It panics if copying fails, e.g. a registered converter returns an error.
 func (c Copier) Copy(dst, src interface{}) {
-	// More safe and independent from internal structs
-	// srcValue := reflect.ValueOf(src)
-	// if srcValue.Kind() != reflect.Ptr {
-	// 	panic("source must be pointer to struct")
-	// }
-	// srcPtr := unsafe.Pointer(srcValue.Pointer())
-	// srcValue = srcValue.Elem()
-	// dstValue := reflect.ValueOf(dst)
-	// if dstValue.Kind() != reflect.Ptr {
-	// 	panic("source must be pointer to struct")
-	// }
-	// dstPtr := unsafe.Pointer(dstValue.Pointer())
-	// dstValue = dstValue.Elem()
+	if len(c.copiers) == 0 {
+		c.copyValues(ifaceToPtr(dst), ifaceToPtr(src))
+		return
+	}
+
+	if err := c.CopyContext(context.Background(), dst, src); err != nil {
+		panic(err)
+	}
+}
+
+// CopyContext copies the contents of src into dst. Dst and src each must be a pointer to struct.
+// The context is passed to registered converters and hooks, the cancellation is checked
+// between elements of copied slices.
+func (c Copier) CopyContext(ctx context.Context, dst, src interface{}) error {
 	dstPtr := ifaceToPtr(dst)
 	srcPtr := ifaceToPtr(src)
 
-	c.copy(dstPtr, srcPtr)
+	return c.copyRoot(ctx, dstPtr, srcPtr)
+}
+
+// copyRoot copies the structs passed by the caller. The root structs of recursive types are tracked,
+// so pointers to the source root are pointed to the destination root.
+func (c Copier) copyRoot(ctx context.Context, dst, src pointer) error {
+	if c.recursive {
+		visits := map[visit]pointer{{src: addrKey(src), dst: c.dst}: dst}
+		ctx = context.WithValue(ctx, visitsKey{}, visits)
+	}
+
+	return c.copy(ctx, dst, src)
 }
 
-func (c Copier) copy(dst, src unsafe.Pointer) {
+func (c Copier) copy(ctx context.Context, dst, src pointer) error {
+	c.copyValues(dst, src)
 	for _, c := range c.copiers {
+		if err := c(ctx, dst, src); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyValues copies plain values.
+func (c Copier) copyValues(dst, src pointer) {
+	for _, c := range c.values {
 		c(dst, src)
 	}
 }
@@ -269,57 +908,20 @@ var defaultCopier = New(Tag(defaultTagName))
 //
 //   copy.Prepare(&dst, &src)
 func Prepare(dst, src interface{}) {
-	defaultCopier.Prepare(src, dst)
+	defaultCopier.Prepare(dst, src)
 }
 
 // Copy copies the contents of src into dst. Dst and src each must be a pointer to a struct.
 func Copy(dst, src interface{}) {
-	defaultCopier.Copy(src, dst)
+	defaultCopier.Copy(dst, src)
 }
 
-// Get Copier for a specific destination and source.
-func Get(dst, src interface{}) Copier {
-	return defaultCopier.Get(src, dst)
-}
-
-// More safe and independent from internal structs
-// func ifaceToPtr(i interface{}) unsafe.Pointer {
-// 	v := reflect.ValueOf(i)
-// 	if v.Kind() != reflect.Ptr {
-// 		panic("source must be pointer to struct")
-// 	}
-// 	return unsafe.Pointer(v.Pointer())
-// }
-
-func ifaceToPtr(i interface{}) unsafe.Pointer {
-	if i == nil {
-		panic("input parameter is nil")
-	}
-
-	type iface struct {
-		Type, Data unsafe.Pointer
-	}
-
-	return (*iface)(unsafe.Pointer(&i)).Data
+// CopyContext copies the contents of src into dst. Dst and src each must be a pointer to a struct.
+func CopyContext(ctx context.Context, dst, src interface{}) error {
+	return defaultCopier.CopyContext(ctx, dst, src)
 }
 
-func memcopy(dst, src unsafe.Pointer, size int) {
-	var srcSlice []byte
-	srcSH := (*reflect.SliceHeader)((unsafe.Pointer(&srcSlice)))
-	srcSH.Data = uintptr(src)
-	srcSH.Cap = int(size)
-	srcSH.Len = int(size)
-
-	var dstSlice []byte
-	dstSH := (*reflect.SliceHeader)((unsafe.Pointer(&dstSlice)))
-	dstSH.Data = uintptr(dst)
-	dstSH.Cap = int(size)
-	dstSH.Len = int(size)
-
-	copy(dstSlice, srcSlice)
-}
-
-func alloc(size int) unsafe.Pointer {
-	size = (size + 7) / 8 // size in int64
-	return unsafe.Pointer(&(make([]int64, size)[0]))
+// Get Copier for a specific destination and source.
+func Get(dst, src interface{}) Copier {
+	return defaultCopier.Get(dst, src)
 }
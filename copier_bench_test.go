@@ -61,3 +61,67 @@ func BenchmarkCopiers(b *testing.B) {
 		c.Copy(&dst, &src)
 	}
 }
+
+type testPtrStruct struct {
+	S  string
+	I  int
+	BB []bool
+	V  *internal
+}
+
+func BenchmarkCopierStructToPtr(b *testing.B) {
+	c := New()
+	copier := c.Get(&testPtrStruct{}, &src)
+
+	for i := 0; i < b.N; i++ {
+		dst := testPtrStruct{}
+		copier.Copy(&dst, &src)
+	}
+}
+
+type internal2 struct {
+	I int
+}
+
+type testPtrStruct2 struct {
+	S  string
+	I  int
+	BB []bool
+	V  *internal2
+}
+
+func BenchmarkCopierPtrToPtr(b *testing.B) {
+	src := testPtrStruct{S: "string", V: &internal{I: 5}}
+	c := New()
+	copier := c.Get(&testPtrStruct2{}, &src)
+
+	for i := 0; i < b.N; i++ {
+		dst := testPtrStruct2{}
+		copier.Copy(&dst, &src)
+	}
+}
+
+// Run with -cpu=1,8,64 to see how lookups of copiers scale.
+func BenchmarkCopiersParallel(b *testing.B) {
+	c := New()
+	c.Prepare(&dst, &src)
+
+	b.RunParallel(func(pb *testing.PB) {
+		dst := testStruct{}
+		for pb.Next() {
+			c.Copy(&dst, &src)
+		}
+	})
+}
+
+func BenchmarkCopiersLRUParallel(b *testing.B) {
+	c := New(CacheSize(100))
+	c.Prepare(&dst, &src)
+
+	b.RunParallel(func(pb *testing.PB) {
+		dst := testStruct{}
+		for pb.Next() {
+			c.Copy(&dst, &src)
+		}
+	})
+}
@@ -0,0 +1,262 @@
+//go:build copy_safe
+// +build copy_safe
+
+package copy
+
+import (
+	"database/sql"
+	"reflect"
+	"time"
+
+	"github.com/gotidy/copy/structinfo"
+)
+
+// pointer is an addressable value.
+type pointer = reflect.Value
+
+// valuePointer returns the value the pointer value v points to.
+func valuePointer(v reflect.Value) pointer {
+	return v.Elem()
+}
+
+// valueAt returns the value of the type at the address.
+func valueAt(p pointer, t reflect.Type) reflect.Value {
+	return p
+}
+
+// fieldAt returns the struct field, that has no embedded pointers on the way to it.
+func fieldAt(p pointer, f structinfo.Field) pointer {
+	return p.FieldByIndex(f.Index)
+}
+
+// fieldPtr returns the struct field and false if an embedded pointer on the way to it is nil.
+func fieldPtr(p pointer, f structinfo.Field) (pointer, bool) {
+	return fieldValue(p, f)
+}
+
+// fieldPtrAlloc returns the struct field, nil embedded pointers on the way to it are allocated.
+func fieldPtrAlloc(p pointer, f structinfo.Field) pointer {
+	return fieldValueAlloc(p, f)
+}
+
+// deref returns the value the pointer p points to and false if the pointer is nil.
+func deref(p pointer) (pointer, bool) {
+	if p.IsNil() {
+		return p, false
+	}
+	return p.Elem(), true
+}
+
+// derefAlloc returns the value the pointer p points to, a nil pointer is set to a new value of the type t.
+func derefAlloc(p pointer, t reflect.Type) pointer {
+	if p.IsNil() {
+		p.Set(reflect.New(t))
+	}
+	return p.Elem()
+}
+
+// addrKey returns the address of the value as a map key.
+func addrKey(p pointer) uintptr {
+	return p.UnsafeAddr()
+}
+
+// setPointer sets the pointer to the address of the target value.
+func setPointer(p pointer, target pointer) {
+	p.Set(target.Addr())
+}
+
+// valueCopier returns the copier of values of the types, if it is not found then nil is returned.
+func valueCopier(dst, src reflect.Type) func(dst, src pointer) {
+	if copier := convertCopier(dst, src); copier != nil {
+		return copier
+	}
+
+	// same type -> same type
+	if src == dst || sameElem(dst, src) {
+		return func(dstPtr, srcPtr pointer) {
+			dstPtr.Set(srcPtr.Convert(dst))
+		}
+	}
+
+	return nil
+}
+
+// sameElem reports whether the types are composite types of the same kind with the same elements.
+func sameElem(dst, src reflect.Type) bool {
+	if dst.Kind() != src.Kind() || !src.ConvertibleTo(dst) {
+		return false
+	}
+
+	switch dst.Kind() {
+	case reflect.Array:
+		return dst.Elem() == src.Elem() && dst.Len() == src.Len()
+	case reflect.Ptr, reflect.Slice:
+		return dst.Elem() == src.Elem()
+	case reflect.Chan:
+		return dst.Elem() == src.Elem() && src.ChanDir()&dst.ChanDir() == dst.ChanDir()
+	case reflect.Map:
+		return dst.Elem() == src.Elem() && dst.Key() == src.Key()
+	}
+
+	return false
+}
+
+// Groups of types, which values are converted to each other. It is the same set of types as funcs package has.
+var convertGroups = func() map[reflect.Type]int {
+	groups := [][]interface{}{
+		{
+			int(0), int8(0), int16(0), int32(0), int64(0),
+			uint(0), uint8(0), uint16(0), uint32(0), uint64(0),
+		},
+		{float32(0), float64(0)},
+		{false},
+		{complex64(0), complex128(0)},
+		{"", []byte(nil)},
+		{time.Time{}},
+		{time.Duration(0)},
+	}
+
+	m := make(map[reflect.Type]int)
+	for i, group := range groups {
+		for _, v := range group {
+			m[reflect.TypeOf(v)] = i
+		}
+	}
+	return m
+}()
+
+// SQL null types and groups of types of their values.
+var nullGroups = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int(0)),
+	reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int(0)),
+	reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+	reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+	reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+	reflect.TypeOf(sql.NullTime{}):    reflect.TypeOf(time.Time{}),
+}
+
+func indirect(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem(), true
+	}
+	return t, false
+}
+
+func sameGroup(a, b reflect.Type) bool {
+	ga, ok := convertGroups[a]
+	if !ok {
+		return false
+	}
+	gb, ok := convertGroups[b]
+	return ok && ga == gb
+}
+
+// load returns the value or the value the pointer points to and false if the pointer is nil.
+func load(v reflect.Value, ptr bool) (reflect.Value, bool) {
+	if !ptr {
+		return v, true
+	}
+	if v.IsNil() {
+		return v, false
+	}
+	return v.Elem(), true
+}
+
+// store sets the destination to v converted to the destination type, or zeroes it if ok is false.
+func store(dst reflect.Value, ptr bool, v reflect.Value, ok bool) {
+	if !ok {
+		dst.Set(reflect.Zero(dst.Type()))
+		return
+	}
+	if ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+	dst.Set(v.Convert(dst.Type()))
+}
+
+// convertCopier returns the copier converting values of the basic types, their pointers and SQL null types.
+func convertCopier(dst, src reflect.Type) func(dst, src pointer) {
+	dstElem, dstPtr := indirect(dst)
+	srcElem, srcPtr := indirect(src)
+
+	// NULL -> type
+	if group, ok := nullGroups[src]; ok && sameGroup(dstElem, group) {
+		return func(dstValue, srcValue pointer) {
+			valid := srcValue.Field(1).Bool()
+			store(dstValue, dstPtr, srcValue.Field(0), valid || !dstPtr)
+		}
+	}
+
+	// type -> NULL
+	if group, ok := nullGroups[dst]; ok && sameGroup(srcElem, group) {
+		return func(dstValue, srcValue pointer) {
+			v, ok := load(srcValue, srcPtr)
+			if !ok {
+				dstValue.Set(reflect.Zero(dst))
+				return
+			}
+			null := reflect.New(dst).Elem()
+			null.Field(0).Set(v.Convert(null.Field(0).Type()))
+			null.Field(1).SetBool(true)
+			dstValue.Set(null)
+		}
+	}
+
+	if !sameGroup(dstElem, srcElem) {
+		return nil
+	}
+
+	return func(dstValue, srcValue pointer) {
+		v, ok := load(srcValue, srcPtr)
+		store(dstValue, dstPtr, v, ok)
+	}
+}
+
+func ifaceToPtr(i interface{}) pointer {
+	if i == nil {
+		panic("input parameter is nil")
+	}
+
+	v := reflect.ValueOf(i)
+	if v.Kind() != reflect.Ptr {
+		panic("input parameter must be pointer to struct")
+	}
+
+	return v.Elem()
+}
+
+// addrOf returns the pointer value to the value.
+func addrOf(p pointer, t reflect.Type) reflect.Value {
+	return p.Addr()
+}
+
+// setZero sets the value to zero.
+func setZero(p pointer, t reflect.Type) {
+	p.Set(reflect.Zero(t))
+}
+
+func sliceIsNil(p pointer) bool {
+	return p.IsNil()
+}
+
+func sliceLen(p pointer) int {
+	return p.Len()
+}
+
+// sliceIndex returns the i'th element of the slice.
+func sliceIndex(p pointer, t reflect.Type, i int) pointer {
+	return p.Index(i)
+}
+
+// makeSlice sets the slice to a new slice of length n.
+func makeSlice(p pointer, t reflect.Type, n int) {
+	p.Set(reflect.MakeSlice(t, n, n))
+}
+
+// valueAddr returns the addressable value.
+func valueAddr(v reflect.Value) pointer {
+	return v
+}
@@ -0,0 +1,37 @@
+//go:build copy_safe
+// +build copy_safe
+
+package copy
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/gotidy/copy/funcs"
+)
+
+func TestCopier_CopyFuncs(t *testing.T) {
+	type celsius float64
+	type fahrenheit float64
+
+	// Copy functions are not used by the safe mode, so they can not be registered.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("registering of copy functions must panic in the safe mode")
+			}
+		}()
+		funcs.Set(reflect.TypeOf(fahrenheit(0)), reflect.TypeOf(celsius(0)), func(dst, src unsafe.Pointer) {})
+	}()
+
+	c := New(Converter(func(dst *fahrenheit, src celsius) error {
+		*dst = fahrenheit(src*9/5 + 32)
+		return nil
+	}))
+	dst := struct{ T fahrenheit }{}
+	c.Copy(&dst, &struct{ T celsius }{T: 100})
+	if dst.T != 212 {
+		t.Errorf("converter must be used, want «212» got «%v»", dst.T)
+	}
+}
@@ -1,8 +1,23 @@
 package copy
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"net/url"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/gotidy/copy/structinfo"
+	"github.com/gotidy/copy/textfuncs"
+	"github.com/gotidy/ptr"
 )
 
 func trim(b []rune) []rune {
@@ -221,7 +236,7 @@ func TestCopier_Pointer(t *testing.T) {
 
 func TestCopier_Skip(t *testing.T) {
 	src := struct{ S string }{S: "string"}
-	dst := struct{ S int }{}
+	dst := struct{ S []int }{}
 
 	func() {
 		defer func() {
@@ -242,6 +257,42 @@ func TestCopier_Skip(t *testing.T) {
 	}()
 }
 
+func TestCopier_Text(t *testing.T) {
+	type form struct {
+		Age     string
+		Weight  string
+		Timeout string
+		Born    string
+	}
+	type person struct {
+		Age     int
+		Weight  *float64
+		Timeout time.Duration
+		Born    time.Time
+	}
+
+	// Strings are converted by the rules binding url values, headers and environment variables.
+	src := form{Age: "33", Weight: "70.5", Timeout: "1m30s", Born: "2021-02-18T16:00:01Z"}
+	dst := person{}
+	Copy(&dst, &src)
+
+	weight := 70.5
+	expected := person{Age: 33, Weight: &weight, Timeout: 90 * time.Second, Born: time.Date(2021, 2, 18, 16, 0, 1, 0, time.UTC)}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+
+	back := form{}
+	Copy(&back, &dst)
+	if back != src {
+		t.Errorf("want «%+v» got «%+v»", src, back)
+	}
+
+	if err := New().CopyContext(context.Background(), &dst, &form{Age: "old"}); err == nil {
+		t.Error("must fail on not parsable value")
+	}
+}
+
 func TestCopier_Expand(t *testing.T) {
 	type Expanded struct {
 		E string
@@ -366,3 +417,1036 @@ func TestCopier_StructPtr(t *testing.T) {
 		// }
 	}
 }
+
+func TestCopier_KindPolicy(t *testing.T) {
+	type testStruct struct {
+		C chan int
+		F func() int
+		I int
+	}
+
+	src := testStruct{C: make(chan int), F: func() int { return 1 }, I: 1}
+
+	dst := testStruct{}
+	New().Copy(&dst, &src)
+	if dst.C != src.C || dst.F == nil {
+		t.Error("channels and functions must be shared by default")
+	}
+
+	dst = testStruct{}
+	New(KindPolicy(reflect.Chan, PolicySkip), KindPolicy(reflect.Func, PolicySkip)).Copy(&dst, &src)
+	if dst.C != nil || dst.F != nil || dst.I != 1 {
+		t.Errorf("channels and functions must be skipped: %+v", dst)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic when function fields are forbidden")
+			}
+		}()
+		New(KindPolicy(reflect.Func, PolicyError)).Prepare(&dst, &src)
+	}()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on unsupported kind")
+			}
+		}()
+		KindPolicy(reflect.Slice, PolicySkip)
+	}()
+}
+
+func TestCopier_Recursive(t *testing.T) {
+	type node1 struct {
+		Value int
+		Next  *node1
+	}
+
+	type node2 struct {
+		Value int
+		Next  *node2
+	}
+
+	src := node1{Value: 1, Next: &node1{Value: 2, Next: &node1{Value: 3}}}
+	dst := node2{}
+
+	New().Copy(&dst, &src)
+	equal(t, dst, src)
+}
+
+type testParent1 struct {
+	Name  string
+	Child *testChild1
+}
+
+type testChild1 struct {
+	Name   string
+	Parent *testParent1
+}
+
+type testParent2 struct {
+	Name  string
+	Child *testChild2
+}
+
+type testChild2 struct {
+	Name   string
+	Parent *testParent2
+}
+
+func TestCopier_MutuallyRecursive(t *testing.T) {
+	src := testParent1{Name: "parent", Child: &testChild1{Name: "child", Parent: &testParent1{Name: "grandparent"}}}
+	dst := testParent2{}
+
+	c := New()
+	c.Prepare(&dst, &src)
+	c.Copy(&dst, &src)
+	equal(t, dst, src)
+
+	child := testChild2{}
+	c.Copy(&child, src.Child)
+	equal(t, child, src.Child)
+}
+
+func TestCopier_Cyclic(t *testing.T) {
+	type node1 struct {
+		Value int
+		Next  *node1
+	}
+
+	type node2 struct {
+		Value int
+		Next  *node2
+	}
+
+	src := &node1{Value: 1, Next: &node1{Value: 2}}
+	src.Next.Next = src
+	dst := node2{}
+
+	New().Copy(&dst, src)
+	if dst.Value != 1 || dst.Next.Value != 2 || dst.Next.Next != &dst {
+		t.Errorf("want the cycle «1 -> 2 -> 1» got «%d -> %d -> %p»", dst.Value, dst.Next.Value, dst.Next.Next)
+	}
+
+	parent := &testParent1{Name: "parent", Child: &testChild1{Name: "child"}}
+	parent.Child.Parent = parent
+	child := testChild2{}
+
+	New().Copy(&child, parent.Child)
+	if child.Parent == nil || child.Parent.Child == nil || child.Parent.Child.Parent != child.Parent {
+		t.Errorf("want the cycle of the parent and the child got «%+v»", child)
+	}
+}
+
+func TestCopier_AllocGC(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(1))
+
+	type internal1 struct {
+		S  string
+		BB []byte
+		P  *string
+	}
+
+	type internal2 struct {
+		S  string
+		BB []byte
+		P  *string
+	}
+
+	type testStruct1 struct {
+		V  internal1
+		PV *internal1
+	}
+
+	type testStruct2 struct {
+		V  *internal2
+		PV *internal2
+	}
+
+	copier := New().Get(&testStruct2{}, &testStruct1{})
+
+	dsts := make([]testStruct2, 1000)
+	for i := range dsts {
+		s := strings.Repeat(strconv.Itoa(i), 10)
+		src := testStruct1{
+			V:  internal1{S: s, BB: []byte(s), P: &s},
+			PV: &internal1{S: s, BB: []byte(s), P: &s},
+		}
+		copier.Copy(&dsts[i], &src)
+
+		// Produce garbage to trigger collections.
+		_ = make([]byte, 1024)
+	}
+	runtime.GC()
+
+	for i := 0; i < 100; i++ {
+		_ = strings.Repeat("garbage", 100)
+	}
+	runtime.GC()
+
+	for i, dst := range dsts {
+		s := strings.Repeat(strconv.Itoa(i), 10)
+		for _, v := range []*internal2{dst.V, dst.PV} {
+			if v.S != s || string(v.BB) != s || *v.P != s {
+				t.Fatalf("want «%s» got «%s», «%s», «%s»", s, v.S, string(v.BB), *v.P)
+			}
+		}
+	}
+}
+
+func TestCopier_Convert(t *testing.T) {
+	type testStruct1 struct {
+		I  int8
+		PI *int
+		N  sql.NullString
+		S  string
+		B  []byte
+		NT sql.NullInt64
+	}
+
+	type testStruct2 struct {
+		I  int
+		PI int64
+		N  *string
+		S  sql.NullString
+		B  string
+		NT *int32
+	}
+
+	src := testStruct1{
+		I:  5,
+		PI: ptr.Int(10),
+		N:  sql.NullString{String: "null", Valid: true},
+		S:  "string",
+		B:  []byte("bytes"),
+	}
+	dst := testStruct2{NT: ptr.Int32(1)}
+
+	New().Copy(&dst, &src)
+
+	expected := testStruct2{
+		I:  5,
+		PI: 10,
+		N:  ptr.String("null"),
+		S:  sql.NullString{String: "string", Valid: true},
+		B:  "bytes",
+	}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+}
+
+func TestCopier_Ignore(t *testing.T) {
+	type testStruct struct {
+		S   string
+		X_I int
+	}
+
+	src := testStruct{S: "string", X_I: 10}
+	dst := testStruct{}
+
+	c := New(Ignore(func(name string) bool { return strings.HasPrefix(name, "X_") }))
+	c.Copy(&dst, &src)
+
+	expected := testStruct{S: "string"}
+	if dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+
+	// Ignored fields are not compared, read or filled by other mappings.
+	if !c.Equal(&dst, &src) {
+		t.Error("ignored fields must not be compared")
+	}
+	if m := c.ToMap(&src); !reflect.DeepEqual(m, map[string]interface{}{"S": "string"}) {
+		t.Errorf("want «map[S:string]» got «%v»", m)
+	}
+	if values, err := c.ToValues(&src); err != nil || len(values) != 1 {
+		t.Errorf("want only «S» got «%v», error: %v", values, err)
+	}
+
+	dst = testStruct{}
+	if err := c.FromMap(&dst, map[string]interface{}{"S": "string", "X_I": 10}); err != nil || dst != expected {
+		t.Errorf("want «%+v» got «%+v», error: %v", expected, dst, err)
+	}
+	dst = testStruct{}
+	if err := c.FromValues(&dst, url.Values{"S": {"string"}, "X_I": {"10"}}); err != nil || dst != expected {
+		t.Errorf("want «%+v» got «%+v», error: %v", expected, dst, err)
+	}
+	dst = testStruct{}
+	err := c.FromLookup(&dst, "", func(key string) (string, bool) { return "10", key == "X_I" })
+	if err != nil || dst != (testStruct{}) {
+		t.Errorf("want «{}» got «%+v», error: %v", dst, err)
+	}
+}
+
+func TestCopier_Tags(t *testing.T) {
+	type testStruct1 struct {
+		ID       int    `copy:"Key"`
+		Name     string `json:"name,omitempty" db:"user_name"`
+		Email    string `json:",omitempty" db:"mail"`
+		Phone    string `json:",omitempty"`
+		Password string `json:"-" db:"password"`
+		Dash     string `json:"-,"`
+		Note     string `copy:",default=none" json:"-"`
+	}
+
+	type testStruct2 struct {
+		Key      int
+		UserName string `db:"name"`
+		Mail     string `copy:"mail"`
+		Phone    string
+		Password string
+		Minus    string `json:"-,"`
+		Note     string
+	}
+
+	src := testStruct1{ID: 1, Name: "John", Email: "john@example.com", Phone: "123", Password: "secret", Dash: "dash", Note: "note"}
+	dst := testStruct2{}
+
+	New(Tags("copy", "json", "db")).Copy(&dst, &src)
+
+	expected := testStruct2{Key: 1, UserName: "John", Mail: "john@example.com", Phone: "123", Minus: "dash", Note: "note"}
+	if dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+}
+
+func TestCopier_TagOptions(t *testing.T) {
+	type Address struct {
+		City string
+	}
+
+	type testStruct1 struct {
+		S       string `copy:",omitempty"`
+		I       int
+		Born    int64
+		Address Address `copy:",inline"`
+	}
+
+	type testStruct2 struct {
+		S    string
+		I    int    `copy:",omitempty"`
+		Born string `copy:",conv=year,required"`
+		City string
+	}
+
+	c := New(Tag("copy"), NamedConverter("year", func(dst *string, src int64) error {
+		*dst = "year " + strconv.FormatInt(src, 10)
+		return nil
+	}))
+
+	dst := testStruct2{S: "keep", I: 10}
+	c.Copy(&dst, &testStruct1{Born: 1990, Address: Address{City: "Paris"}})
+
+	expected := testStruct2{S: "keep", I: 10, Born: "year 1990", City: "Paris"}
+	if dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+
+	for name, test := range map[string]struct {
+		c        *Copiers
+		dst, src interface{}
+	}{
+		"required":                 {c, &testStruct2{}, &struct{ S string }{}},
+		"not registered converter": {New(Tag("copy")), &testStruct2{}, &testStruct1{}},
+		"not fitting converter": {
+			New(Tag("copy"), NamedConverter("year", func(dst *string, src int) error { return nil })), &testStruct2{}, &testStruct1{},
+		},
+		"unknown option": {c, &struct{ S string }{}, &struct {
+			S string `copy:",unknown"`
+		}{}},
+		"missing value": {c, &struct{ S string }{}, &struct {
+			S string `copy:",default"`
+		}{}},
+		"unexpected value": {c, &struct{ S string }{}, &struct {
+			S string `copy:",omitempty=1"`
+		}{}},
+		"duplicate option": {c, &struct{ S string }{}, &struct {
+			S string `copy:",required,required"`
+		}{}},
+		"exclusive options": {c, &struct{ S string }{}, &struct {
+			S string `copy:",readonly,writeonly"`
+		}{}},
+		"malformed nested tags": {c, &struct{ S string }{}, &struct {
+			Address `copy:"+,omitempty=1"`
+		}{}},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Prepare must panic", name)
+				}
+			}()
+			test.c.Prepare(test.dst, test.src)
+		}()
+	}
+
+	// Options with the "x-" prefix are reserved for extensions.
+	c.Prepare(&struct{ S string }{}, &struct {
+		S string `copy:"s,x-format=upper"`
+	}{})
+}
+
+func TestCopier_ReadOnlyWriteOnly(t *testing.T) {
+	type Model struct {
+		ID      int
+		Version int
+	}
+
+	type entity struct {
+		Model    `copy:"+,readonly"`
+		Name     string
+		Password string `copy:",writeonly"`
+		Created  string `copy:",readonly,default=now"`
+	}
+
+	type dto struct {
+		ID       int
+		Version  int
+		Name     string
+		Password string
+		Created  string
+	}
+
+	c := New(Tag("copy"))
+
+	e := entity{Model: Model{ID: 1, Version: 2}, Name: "John", Password: "hash", Created: "yesterday"}
+	c.Copy(&e, &dto{ID: 10, Version: 20, Name: "Jane", Password: "secret", Created: "today"})
+
+	expected := entity{Model: Model{ID: 1, Version: 2}, Name: "Jane", Password: "secret", Created: "yesterday"}
+	if e != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, e)
+	}
+
+	d := dto{Password: "keep"}
+	c.Copy(&d, &e)
+
+	expectedDTO := dto{ID: 1, Version: 2, Name: "Jane", Password: "keep", Created: "yesterday"}
+	if d != expectedDTO {
+		t.Errorf("want «%+v» got «%+v»", expectedDTO, d)
+	}
+
+	if err := c.FromMap(&e, map[string]interface{}{"ID": 5, "Name": "Bob"}); err != nil {
+		t.Fatalf("from map: %s", err)
+	}
+	if e.ID != 1 || e.Name != "Bob" {
+		t.Errorf("read-only field must not be filled from the map, got «%+v»", e)
+	}
+	if _, ok := c.ToMap(&e)["Password"]; ok {
+		t.Error("write-only field must not be read to the map")
+	}
+}
+
+func TestCopier_Prefix(t *testing.T) {
+	type Address struct {
+		City   string
+		Street string
+	}
+
+	type Contact struct {
+		Address `copy:",prefix"`
+		Phone   string
+	}
+
+	type order struct {
+		Billing  Address `copy:"+,prefix=Billing"`
+		Shipping Address `copy:"+,prefix"`
+		Contact  Contact `copy:"+,prefix=Main"`
+	}
+
+	type flatOrder struct {
+		BillingCity       string
+		BillingStreet     string
+		ShippingCity      string
+		ShippingStreet    string
+		MainAddressCity   string
+		MainAddressStreet string
+		MainPhone         string
+	}
+
+	c := New(Tag("copy"))
+
+	src := flatOrder{
+		BillingCity:     "Paris",
+		BillingStreet:   "Rue",
+		ShippingCity:    "Berlin",
+		ShippingStreet:  "Strasse",
+		MainAddressCity: "Rome",
+		MainPhone:       "123",
+	}
+	dst := order{}
+	c.Copy(&dst, &src)
+
+	expected := order{
+		Billing:  Address{City: "Paris", Street: "Rue"},
+		Shipping: Address{City: "Berlin", Street: "Strasse"},
+		Contact:  Contact{Address: Address{City: "Rome"}, Phone: "123"},
+	}
+	if dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+
+	back := flatOrder{}
+	c.Copy(&back, &dst)
+	if back != src {
+		t.Errorf("want «%+v» got «%+v»", src, back)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on prefix of not inlined field")
+			}
+		}()
+		c.Prepare(&struct{ Billing Address }{}, &struct {
+			Billing Address `copy:",prefix=Billing"`
+		}{})
+	}()
+}
+
+func TestCopier_CustomTag(t *testing.T) {
+	type Address struct {
+		City string
+	}
+
+	type order struct {
+		Billing Address `dto:"+"`
+		ID      int     `dto:"id,readonly" json:"number"`
+	}
+
+	type flatOrder struct {
+		City string
+		ID   int `json:"id"`
+	}
+
+	// The custom tag is parsed with all options, like the copy tag.
+	c := New(Tags("dto", "json"))
+
+	dst := order{ID: 1}
+	c.Copy(&dst, &flatOrder{City: "Paris", ID: 2})
+	if expected := (order{Billing: Address{City: "Paris"}, ID: 1}); dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("must panic on unknown option of the custom tag")
+		}
+	}()
+	New(Tag("dto")).Prepare(&struct {
+		ID int `dto:",unknown"`
+	}{}, &flatOrder{})
+}
+
+func TestCopier_Shadowing(t *testing.T) {
+	type Inner struct {
+		Name string
+		Note string
+	}
+
+	type Audit struct {
+		Note string
+		By   string
+	}
+
+	type Meta struct {
+		Inner
+		By string
+	}
+
+	type testStruct1 struct {
+		Inner
+		Audit
+		Meta
+		Name string
+	}
+
+	type testStruct2 struct {
+		Name string
+		By   string
+	}
+
+	// Name of testStruct1 hides Inner.Name, Meta.By at depth 1 is ambiguous with Audit.By,
+	// Inner.Note and Audit.Note are ambiguous.
+	src := testStruct1{Inner: Inner{Name: "inner"}, Name: "outer"}
+	dst := testStruct2{}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on ambiguous field")
+			}
+		}()
+		New().Copy(&dst, &src)
+	}()
+
+	New(Skip()).Copy(&dst, &src)
+	expected := testStruct2{Name: "outer"}
+	if dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+
+	back := testStruct1{}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on ambiguous destination field")
+			}
+		}()
+		New().Copy(&back, &testStruct2{Name: "outer", By: "me"})
+	}()
+}
+
+func TestCopier_AmbiguousBothSides(t *testing.T) {
+	type A struct {
+		ID int
+		X  int
+	}
+	type B struct {
+		ID int
+		Y  int
+	}
+	type T struct {
+		A
+		B
+	}
+
+	// Structs promoting ambiguous fields are copied whole.
+	src := T{A: A{ID: 1, X: 2}, B: B{ID: 3, Y: 4}}
+	dst := T{}
+	New().Copy(&dst, &src)
+	if dst != src {
+		t.Errorf("want «%+v» got «%+v»", src, dst)
+	}
+	if New().Equal(&T{A: A{ID: 9, X: 2}, B: src.B}, &src) {
+		t.Error("ambiguous fields must be compared")
+	}
+
+	type A2 struct {
+		ID int
+		X  int
+	}
+	type B2 struct {
+		ID int
+		Y  int
+	}
+	type T2 struct {
+		A2
+		B2
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("must panic on the field ambiguous on both sides")
+		}
+	}()
+	New().Copy(&T2{}, &src)
+}
+
+func TestCopier_EmbeddedPointer(t *testing.T) {
+	type Base struct {
+		ID   int
+		Tags []string
+	}
+
+	type Audit struct {
+		*Base
+		By string
+	}
+
+	type testStruct1 struct {
+		*Audit
+		Name string
+	}
+
+	type testStruct2 struct {
+		*Base
+		By   string
+		Name string
+	}
+
+	c := New()
+
+	// Nil embedded pointers of the source are skipped.
+	dst := testStruct2{}
+	c.Copy(&dst, &testStruct1{Name: "name"})
+	if dst.Base != nil || dst.Name != "name" {
+		t.Errorf("nil embedded pointers must be skipped, got «%+v»", dst)
+	}
+
+	c.Copy(&dst, &testStruct1{Audit: &Audit{By: "me"}, Name: "name"})
+	if dst.Base != nil || dst.By != "me" {
+		t.Errorf("nil embedded pointers must be skipped, got «%+v»", dst)
+	}
+
+	// Embedded pointers of the destination are allocated.
+	src := testStruct1{Audit: &Audit{Base: &Base{ID: 1, Tags: []string{"a"}}, By: "me"}, Name: "name"}
+	dst = testStruct2{}
+	c.Copy(&dst, &src)
+
+	expected := testStruct2{Base: &Base{ID: 1, Tags: []string{"a"}}, By: "me", Name: "name"}
+	if !reflect.DeepEqual(dst, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+	if dst.Base == src.Base {
+		t.Error("embedded pointers must not be shared")
+	}
+
+	back := testStruct1{}
+	c.Copy(&back, &dst)
+	if !reflect.DeepEqual(back, src) {
+		t.Errorf("want «%+v» got «%+v»", src, back)
+	}
+
+	m := c.ToMap(&testStruct1{Name: "name"})
+	if !reflect.DeepEqual(m, map[string]interface{}{"Name": "name"}) {
+		t.Errorf("fields of nil embedded pointers must be omitted, got «%v»", m)
+	}
+
+	if !c.Equal(&dst, &src) {
+		t.Error("copied structs must be equal")
+	}
+}
+
+func TestCopier_EmbeddedPointerCycle(t *testing.T) {
+	type Node struct {
+		*Node
+		V int
+	}
+
+	dst := Node{}
+	New().Copy(&dst, &Node{Node: &Node{V: 2}, V: 1})
+	if dst.V != 1 || dst.Node == nil || dst.Node.V != 2 {
+		t.Errorf("want «1 -> 2» got «%+v»", dst)
+	}
+}
+
+func TestCopier_EmbeddedWithoutFields(t *testing.T) {
+	type E1 struct {
+		time.Time
+		Name string
+	}
+	type E2 struct {
+		time.Time
+		Name string
+	}
+
+	want := E1{Time: time.Unix(100, 0), Name: "name"}
+
+	same := E1{}
+	New().Copy(&same, &want)
+	if !same.Equal(want.Time) || same.Name != want.Name {
+		t.Errorf("want «%v» got «%v»", want, same)
+	}
+
+	other := E2{}
+	New().Copy(&other, &want)
+	if !other.Equal(want.Time) || other.Name != want.Name {
+		t.Errorf("want «%v» got «%v»", want, other)
+	}
+}
+
+func TestCopier_EmbeddedWhole(t *testing.T) {
+	type Base struct {
+		ID     int
+		Name   string
+		secret string
+	}
+	type Outer struct {
+		Base
+		ID int
+	}
+	type OuterPtr struct {
+		*Base
+		ID int
+	}
+
+	// Embedded structs are copied whole with hidden and unexported fields.
+	src := Outer{Base: Base{ID: 1, Name: "name", secret: "s"}, ID: 2}
+	dst := Outer{}
+	New().Copy(&dst, &src)
+	if dst != src {
+		t.Errorf("want «%+v» got «%+v»", src, dst)
+	}
+	if Equal(&Outer{ID: 2}, &src) {
+		t.Error("hidden fields of embedded structs must be compared")
+	}
+
+	srcPtr := OuterPtr{Base: &Base{ID: 1, Name: "name", secret: "s"}, ID: 2}
+	dstPtr := OuterPtr{}
+	New().Copy(&dstPtr, &srcPtr)
+	if dstPtr.Base == nil || *dstPtr.Base != *srcPtr.Base || dstPtr.ID != 2 {
+		t.Errorf("want «%+v» got «%+v»", srcPtr, dstPtr)
+	}
+	if dstPtr.Base == srcPtr.Base {
+		t.Error("embedded pointers must not be shared")
+	}
+
+	// Conditions on promoted fields copy the embedded structs field by field.
+	c := New()
+	c.Map(&Outer{}, &Outer{}, When("Name", func(*Outer) bool { return false }))
+	dst = Outer{}
+	c.Copy(&dst, &src)
+	if expected := (Outer{ID: 2}); dst != expected {
+		t.Errorf("want «%+v» got «%+v»", expected, dst)
+	}
+}
+
+func TestCopier_FloatToInt(t *testing.T) {
+	type F1 struct {
+		X float64
+	}
+	type F2 struct {
+		X uint8
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("copying of float to integer must panic")
+		}
+	}()
+
+	New().Copy(&F2{}, &F1{X: 300.7})
+}
+
+func TestCopiers_CacheSize(t *testing.T) {
+	type A struct{ V int }
+	type B struct{ V int }
+	type C struct{ V int }
+
+	c := New(CacheSize(1))
+	c.Copy(&B{}, &A{})
+	c.Copy(&B{}, &A{})
+	c.Copy(&C{}, &A{})
+
+	stats := c.Stats().Copiers
+	if stats.Hits != 1 || stats.Evictions != 1 || stats.Entries != 1 {
+		t.Errorf("want «1» hit, «1» eviction and «1» entry got «%+v»", stats)
+	}
+	if c.Stats().Structs.Entries != 1 {
+		t.Errorf("want «1» struct info got «%+v»", c.Stats().Structs)
+	}
+
+	dst := B{}
+	c.Copy(&dst, &A{V: 1})
+	if dst.V != 1 {
+		t.Errorf("want «1» got «%d»", dst.V)
+	}
+}
+
+func TestCopiers_Forget(t *testing.T) {
+	type A struct{ V int }
+	type B struct{ V int }
+
+	c := New()
+	c.Prepare(&B{}, &A{})
+	c.Forget(&B{}, &A{})
+	if got := c.Stats().Copiers.Entries; got != 0 {
+		t.Errorf("want «0» copiers got «%d»", got)
+	}
+
+	// The pair can be mapped again after the copier is forgotten.
+	c.Map(&B{}, &A{}, Default("V", 2))
+	dst := B{}
+	c.Copy(&dst, &A{})
+	if dst.V != 2 {
+		t.Errorf("want «2» got «%d»", dst.V)
+	}
+
+	c.Reset()
+	if got := c.Stats(); got.Copiers != (structinfo.CacheStats{}) {
+		t.Errorf("want empty stats got «%+v»", got.Copiers)
+	}
+
+	// Struct infos shared with other copiers are kept.
+	shared := structinfo.Shared("forget")
+	New(Tag("forget")).Prepare(&B{}, &A{})
+	other := New(Tag("forget"))
+	other.Forget(&B{}, &A{})
+	other.Reset()
+	if got := shared.Stats().Entries; got != 2 {
+		t.Errorf("want «2» shared struct infos got «%d»", got)
+	}
+	if got := other.Stats().Structs; got != (structinfo.CacheStats{}) {
+		t.Errorf("want no stats of the shared cache got «%+v»", got)
+	}
+}
+
+func TestCopiers_ConcurrentPrepare(t *testing.T) {
+	type InnerA struct{ V int }
+	type InnerB struct{ V int }
+	type A struct {
+		Inner InnerA
+		Next  *A
+	}
+	type B struct {
+		Inner InnerB
+		Next  *B
+	}
+
+	// The copiers have their own struct infos, so other tests do not affect statistics.
+	c := New(CacheSize(100))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			dst := B{}
+			c.Copy(&dst, &A{Inner: InnerA{V: 1}, Next: &A{}})
+			if dst.Inner.V != 1 || dst.Next == nil {
+				t.Errorf("want «{1} -> {}» got «%+v»", dst)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	// Copiers of A to B and InnerA to InnerB are prepared once.
+	if stats := c.Stats().Copiers; stats.Misses != 2 || stats.Entries != 2 {
+		t.Errorf("want «2» misses and «2» entries got «%+v»", stats)
+	}
+	if misses := c.Stats().Structs.Misses; misses != 4 {
+		t.Errorf("want «4» struct misses got «%d»", misses)
+	}
+}
+
+// slowText is parsed slowly, defaults of its fields make preparing of copiers slow.
+type slowText int
+
+var slowTextParsed int32
+
+func init() {
+	textfuncs.SetParse(reflect.TypeOf(slowText(0)), func(v reflect.Value, s string) error {
+		atomic.AddInt32(&slowTextParsed, 1)
+		time.Sleep(10 * time.Millisecond)
+		v.SetInt(1)
+		return nil
+	})
+}
+
+func TestCopiers_ConcurrentEqual(t *testing.T) {
+	type InnerA struct{ V int }
+	type InnerB struct {
+		V int
+		D slowText `copy:",default=1"`
+	}
+	type A struct{ Items []InnerA }
+	type B struct{ Items []InnerB }
+
+	// The default value is parsed while the copier is prepared, so concurrent preparing would be noticed.
+	c := New(Tag("copy"), CacheSize(100))
+	parsed := atomic.LoadInt32(&slowTextParsed)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if !c.Equal(&B{Items: []InnerB{{V: 1, D: 1}}}, &A{Items: []InnerA{{V: 1}}}) {
+				t.Error("structs must be equal")
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	// The copier of InnerA to InnerB converting items is prepared once.
+	if n := atomic.LoadInt32(&slowTextParsed) - parsed; n != 1 {
+		t.Errorf("want the copier prepared once got «%d» times", n)
+	}
+	if stats := c.Stats().Copiers; stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("want «1» miss and «1» entry got «%+v»", stats)
+	}
+}
+
+func TestCopiers_ParallelPrepare(t *testing.T) {
+	type Gate struct{ V int }
+	type GateDTO struct {
+		V int
+		D gateText `copy:",default=1"`
+	}
+	type Other struct{ V int }
+
+	gate := make(chan struct{})
+	gateTexts.Store(gate)
+	c := New(Tag("copy"))
+
+	// The copier of Gate waits for the gate, while the copier of the other pair is prepared.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Get(&GateDTO{}, &Gate{})
+	}()
+	<-gateTexts.entered
+
+	prepared := make(chan struct{})
+	go func() {
+		defer close(prepared)
+		c.Get(&Other{}, &Other{})
+	}()
+	select {
+	case <-prepared:
+	case <-time.After(5 * time.Second):
+		t.Error("copiers of different pairs must be prepared in parallel")
+	}
+
+	close(gate)
+	<-done
+}
+
+// gateText is parsed when the gate is open, defaults of its fields hold preparing of copiers.
+type gateText int
+
+var gateTexts = struct {
+	atomic.Value // The gate.
+	entered      chan struct{}
+}{entered: make(chan struct{}, 1)}
+
+func init() {
+	textfuncs.SetParse(reflect.TypeOf(gateText(0)), func(v reflect.Value, s string) error {
+		gateTexts.entered <- struct{}{}
+		<-gateTexts.Load().(chan struct{})
+		return nil
+	})
+}
+
+func TestCopiers_TypeHookGet(t *testing.T) {
+	type Item struct{ V int }
+	type ItemDTO struct{ V int }
+	type Order struct{ Items []Item }
+	type OrderDTO struct{ Items []ItemDTO }
+
+	// Type hooks may get copiers, including the copier of their own pair.
+	var c *Copiers
+	c = New(TypeHook(func(dst, src reflect.Type) HookFunc {
+		if dst != reflect.TypeOf(OrderDTO{}) {
+			return nil
+		}
+		c.Get(&ItemDTO{}, &Item{})
+		c.Get(&OrderDTO{}, &Order{})
+		return func(ctx context.Context, dst, src reflect.Value) error {
+			dst.Interface().(*OrderDTO).Items = append(dst.Interface().(*OrderDTO).Items, ItemDTO{V: 2})
+			return nil
+		}
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dst := OrderDTO{}
+		c.Copy(&dst, &Order{Items: []Item{{V: 1}}})
+		if expected := []ItemDTO{{V: 1}, {V: 2}}; !reflect.DeepEqual(dst.Items, expected) {
+			t.Errorf("want «%+v» got «%+v»", expected, dst.Items)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("type hook getting copiers must not deadlock")
+	}
+}
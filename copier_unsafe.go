@@ -0,0 +1,165 @@
+//go:build !copy_safe
+// +build !copy_safe
+
+package copy
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/gotidy/copy/funcs"
+	"github.com/gotidy/copy/structinfo"
+)
+
+// pointer is an address of a value.
+type pointer = unsafe.Pointer
+
+// valuePointer returns the address the pointer value v points to.
+func valuePointer(v reflect.Value) pointer {
+	return unsafe.Pointer(v.Pointer())
+}
+
+// valueAt returns the value of the type at the address.
+func valueAt(p pointer, t reflect.Type) reflect.Value {
+	return reflect.NewAt(t, p).Elem()
+}
+
+// fieldAt returns the address of the struct field, that has no embedded pointers on the way to it.
+func fieldAt(p pointer, f structinfo.Field) pointer {
+	return unsafe.Pointer(uintptr(p) + f.Offset)
+}
+
+// fieldPtr returns the address of the struct field and false if an embedded pointer on the way to it is nil.
+func fieldPtr(p pointer, f structinfo.Field) (pointer, bool) {
+	for _, step := range f.Path {
+		var ok bool
+		if p, ok = deref(unsafe.Pointer(uintptr(p) + step.Offset)); !ok {
+			return nil, false
+		}
+	}
+	return unsafe.Pointer(uintptr(p) + f.Offset), true
+}
+
+// fieldPtrAlloc returns the address of the struct field, nil embedded pointers on the way to it are allocated.
+func fieldPtrAlloc(p pointer, f structinfo.Field) pointer {
+	for _, step := range f.Path {
+		p = derefAlloc(unsafe.Pointer(uintptr(p)+step.Offset), step.Type)
+	}
+	return unsafe.Pointer(uintptr(p) + f.Offset)
+}
+
+// deref returns the address the pointer at p points to and false if the pointer is nil.
+func deref(p pointer) (pointer, bool) {
+	p = *(*unsafe.Pointer)(p)
+	return p, p != nil
+}
+
+// derefAlloc returns the address the pointer at p points to, a nil pointer is set to a new value of the type t.
+func derefAlloc(p pointer, t reflect.Type) pointer {
+	ptr := (*unsafe.Pointer)(p)
+	if *ptr == nil {
+		*ptr = alloc(t)
+	}
+	return *ptr
+}
+
+// addrKey returns the address as a map key.
+func addrKey(p pointer) uintptr {
+	return uintptr(p)
+}
+
+// setPointer sets the pointer at p to the target address.
+func setPointer(p pointer, target pointer) {
+	*(*unsafe.Pointer)(p) = target
+}
+
+// valueCopier returns the copier of values of the types, if it is not found then nil is returned.
+func valueCopier(dst, src reflect.Type) func(dst, src pointer) {
+	if copier := funcs.Get(dst, src); copier != nil {
+		return copier
+	}
+
+	// same type -> same type
+	if src == dst {
+		size := int(src.Size())
+
+		return func(dstPtr, srcPtr pointer) {
+			memcopy(dstPtr, srcPtr, size)
+		}
+	}
+
+	return nil
+}
+
+func ifaceToPtr(i interface{}) pointer {
+	if i == nil {
+		panic("input parameter is nil")
+	}
+
+	type iface struct {
+		Type, Data unsafe.Pointer
+	}
+
+	return (*iface)(unsafe.Pointer(&i)).Data
+}
+
+func memcopy(dst, src unsafe.Pointer, size int) {
+	var srcSlice []byte
+	srcSH := (*reflect.SliceHeader)((unsafe.Pointer(&srcSlice)))
+	srcSH.Data = uintptr(src)
+	srcSH.Cap = int(size)
+	srcSH.Len = int(size)
+
+	var dstSlice []byte
+	dstSH := (*reflect.SliceHeader)((unsafe.Pointer(&dstSlice)))
+	dstSH.Data = uintptr(dst)
+	dstSH.Cap = int(size)
+	dstSH.Len = int(size)
+
+	copy(dstSlice, srcSlice)
+}
+
+// alloc allocates a zero value of the type. The memory is typed, so the garbage collector
+// scans pointers of the value.
+func alloc(t reflect.Type) unsafe.Pointer {
+	return unsafe.Pointer(reflect.New(t).Pointer())
+}
+
+// addrOf returns the pointer value to the value of the type at the address.
+func addrOf(p pointer, t reflect.Type) reflect.Value {
+	return reflect.NewAt(t, p)
+}
+
+// setZero sets the value of the type at the address to zero.
+func setZero(p pointer, t reflect.Type) {
+	reflect.NewAt(t, p).Elem().Set(reflect.Zero(t))
+}
+
+type sliceHeader struct {
+	Data unsafe.Pointer
+	Len  int
+	Cap  int
+}
+
+func sliceIsNil(p pointer) bool {
+	return (*sliceHeader)(p).Data == nil
+}
+
+func sliceLen(p pointer) int {
+	return (*sliceHeader)(p).Len
+}
+
+// sliceIndex returns the address of the i'th element of the slice with elements of the type t.
+func sliceIndex(p pointer, t reflect.Type, i int) pointer {
+	return unsafe.Pointer(uintptr((*sliceHeader)(p).Data) + uintptr(i)*t.Size())
+}
+
+// makeSlice sets the slice of the type t at the address to a new slice of length n.
+func makeSlice(p pointer, t reflect.Type, n int) {
+	reflect.NewAt(t, p).Elem().Set(reflect.MakeSlice(t, n, n))
+}
+
+// valueAddr returns the address of the addressable value.
+func valueAddr(v reflect.Value) pointer {
+	return unsafe.Pointer(v.UnsafeAddr())
+}
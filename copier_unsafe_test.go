@@ -0,0 +1,27 @@
+//go:build !copy_safe
+// +build !copy_safe
+
+package copy
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/gotidy/copy/funcs"
+)
+
+func TestCopier_CopyFuncs(t *testing.T) {
+	type celsius float64
+	type fahrenheit float64
+
+	funcs.Set(reflect.TypeOf(fahrenheit(0)), reflect.TypeOf(celsius(0)), func(dst, src unsafe.Pointer) {
+		*(*fahrenheit)(dst) = fahrenheit(*(*celsius)(src)*9/5 + 32)
+	})
+
+	dst := struct{ T fahrenheit }{}
+	New().Copy(&dst, &struct{ T celsius }{T: 100})
+	if dst.T != 212 {
+		t.Errorf("copy function must be used, want «212» got «%v»", dst.T)
+	}
+}
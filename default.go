@@ -0,0 +1,100 @@
+package copy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/gotidy/copy/structinfo"
+	"github.com/gotidy/copy/textfuncs"
+)
+
+// Default sets the default value of the destination field, that is set when the source has no such field
+// or the source field is zero. The value must be convertible to the field type or to the type the field points to.
+//
+//   c.Map(&UserDTO{}, &User{}, copy.Default("Role", "guest"))
+func Default(field string, value interface{}) MappingOption {
+	return func(m *mapping) {
+		if m.defaults == nil {
+			m.defaults = make(map[string]reflect.Value)
+		}
+		m.defaults[field] = reflect.ValueOf(value)
+	}
+}
+
+// defaultSetter returns the function setting the value to the value of the field type. For pointer fields
+// a new value is allocated each time.
+func defaultSetter(t reflect.Type, v reflect.Value) (func(dst reflect.Value), error) {
+	elem := t
+	if t.Kind() == reflect.Ptr {
+		elem = t.Elem()
+	}
+
+	switch {
+	case v.Type().ConvertibleTo(elem):
+		v = v.Convert(elem)
+	case v.Type() == t && !v.IsNil():
+		v = v.Elem()
+	default:
+		return nil, fmt.Errorf("default value of type «%s» is not convertible to «%s»", v.Type(), t)
+	}
+
+	switch elem.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Chan, reflect.Func, reflect.Ptr, reflect.Interface:
+		return nil, fmt.Errorf("default value of type «%s» is not supported", t)
+	}
+
+	if t.Kind() == reflect.Ptr {
+		return func(dst reflect.Value) {
+			p := reflect.New(elem)
+			p.Elem().Set(v)
+			dst.Set(p)
+		}, nil
+	}
+
+	return func(dst reflect.Value) {
+		dst.Set(v)
+	}, nil
+}
+
+// fieldDefault returns the function setting the default value of the field, if the field has no default value
+// then nil is returned. The value set by the mapping takes precedence over the value set by the tag.
+func fieldDefault(f structinfo.Field, m *mapping) func(dst reflect.Value) {
+	var set func(dst reflect.Value)
+	var err error
+
+	if v, ok := m.defaultValue(f.Name); ok {
+		set, err = defaultSetter(f.Type, v)
+	} else if f.Default != "" {
+		v := reflect.New(f.Type).Elem()
+		if err = textfuncs.Parse(v, f.Default); err == nil {
+			set, err = defaultSetter(f.Type, v)
+		}
+	}
+
+	if err != nil {
+		panic(fmt.Errorf("default value of field «%s»: %w", f.Name, err))
+	}
+
+	return set
+}
+
+// defaultValueCopier returns the copier setting the default value, when the source field is zero.
+func defaultValueCopier(copier fieldCopier, dst, src structinfo.Field, set func(dst reflect.Value)) fieldCopier {
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		if p, ok := fieldPtr(srcPtr, src); ok && !valueAt(p, src.Type).IsZero() {
+			return copier(ctx, dstPtr, srcPtr)
+		}
+
+		set(valueAt(fieldPtrAlloc(dstPtr, dst), dst.Type))
+		return nil
+	}
+}
+
+// defaultFieldCopier returns the copier setting the default value of the destination field missing from the source.
+func defaultFieldCopier(dst structinfo.Field, set func(dst reflect.Value)) fieldCopier {
+	return func(_ context.Context, dstPtr, _ pointer) error {
+		set(valueAt(fieldPtrAlloc(dstPtr, dst), dst.Type))
+		return nil
+	}
+}
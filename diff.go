@@ -0,0 +1,244 @@
+package copy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/gotidy/copy/structinfo"
+)
+
+// Change is a difference between values of a field.
+type Change struct {
+	Path string      // Dot separated names of the field and the fields of structs containing it.
+	Old  interface{} // Value of the first struct field.
+	New  interface{} // Value of the second struct field.
+}
+
+// Diff returns differences of the fields of a and b, which would be copied from a to b.
+// A and b each must be a struct or a pointer to struct. Fields are matched by the same rules as copying,
+// values of a are converted to types of b before comparing. Nested structs are compared field by field.
+// Masked values are compared, fields with roles and empty fields with omitempty are not compared.
+//
+//   for _, change := range c.Diff(&row, &dto) {
+//       log.Printf("%s: %v -> %v", change.Path, change.Old, change.New)
+//   }
+func (c *Copiers) Diff(a, b interface{}) []Change {
+	src := structValue(a, "source")
+	dst := structValue(b, "destination")
+
+	var changes []Change
+	c.compare(dst, src, "", make(map[comparison]bool), func(path string, dst, src reflect.Value) bool {
+		changes = append(changes, Change{Path: path, Old: src.Interface(), New: dst.Interface()})
+		return true
+	})
+
+	return changes
+}
+
+// Diff returns differences of the fields of a and b, which would be copied from a to b.
+func Diff(a, b interface{}) []Change {
+	return defaultCopier.Diff(a, b)
+}
+
+// structValue returns an addressable struct value.
+func structValue(i interface{}, name string) reflect.Value {
+	v := reflect.ValueOf(i)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	} else if v.IsValid() {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		panic(name + " must be struct")
+	}
+
+	return v
+}
+
+// comparison is a pair of structs being compared.
+type comparison struct {
+	dst, src         uintptr
+	dstType, srcType reflect.Type
+}
+
+// compare calls changed for every field of src which value differs from the value of the mapped dst field.
+// Comparing stops when changed returns false, compare returns false in this case.
+// Structs being compared on the path are not compared again, so cyclic values are compared.
+func (c *Copiers) compare(dst, src reflect.Value, path string, visiting map[comparison]bool,
+	changed func(path string, dst, src reflect.Value) bool) bool {
+	if dst.CanAddr() && src.CanAddr() {
+		key := comparison{dst: dst.UnsafeAddr(), src: src.UnsafeAddr(), dstType: dst.Type(), srcType: src.Type()}
+		if visiting[key] {
+			return true
+		}
+		visiting[key] = true
+		defer delete(visiting, key)
+	}
+
+	srcStruct := c.cache.GetByType(src.Type())
+	dstStruct := c.cache.GetByType(dst.Type())
+
+	m := c.mapping(dst.Type(), src.Type())
+
+	var whole [][2]structinfo.Field // Embedded destination and source structs compared whole.
+	for i := 0; i < srcStruct.NumField(); i++ {
+		srcField := srcStruct.Field(i)
+		dstField, ok := dstStruct.FieldByName(srcField.Name)
+		if !ok || c.ignored(srcField.Name) || srcField.Options.WriteOnly || dstField.Options.ReadOnly ||
+			!c.comparable(dstField.Type, srcField.Type) || wholeEmbedded(whole, dstField, srcField) {
+			continue
+		}
+		// Embedded structs are compared the way they are copied.
+		if srcField.Anonymous && dstField.Anonymous {
+			if c.splitEmbedded(dstStruct, srcStruct, dstField, srcField, m) {
+				continue
+			}
+			whole = append(whole, [2]structinfo.Field{dstField, srcField})
+		}
+
+		fieldPath := dstField.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		// Fields of nil embedded pointers of the source, fields denied by roles and empty fields
+		// with omitempty are not copied, so they are not compared. Masked values are compared.
+		srcValue, ok := c.readValue(context.Background(), src, srcField, dstField)
+		if !ok {
+			continue
+		}
+		dstValue, ok := fieldValue(dst, dstField)
+		if !ok {
+			dstValue = reflect.Zero(dstField.Type)
+		}
+
+		if !c.compareValues(dstValue, srcValue, fieldPath, visiting, changed) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// comparable reports whether the fields of the types are compared. Functions and skipped kinds are not compared.
+func (c *Copiers) comparable(dst, src reflect.Type) bool {
+	for _, kind := range []reflect.Kind{src.Kind(), dst.Kind()} {
+		if kind == reflect.Func || c.options.policies[kind] != PolicyShare {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Copiers) compareValues(dst, src reflect.Value, path string, visiting map[comparison]bool,
+	changed func(path string, dst, src reflect.Value) bool) bool {
+	if c.nested(dst.Type(), src.Type()) {
+		dstElem, dstOk := indirectValue(dst)
+		srcElem, srcOk := indirectValue(src)
+		switch {
+		case !dstOk && !srcOk:
+			return true
+		case dstOk != srcOk:
+			return changed(path, dst, src)
+		}
+		return c.compare(dstElem, srcElem, path, visiting, changed)
+	}
+
+	copier := c.valueConverter(dst.Type(), src.Type())
+	if copier == nil {
+		// Not assignable fields are not copied, so they are not compared.
+		return true
+	}
+
+	v := reflect.New(dst.Type()).Elem()
+	if err := copier(context.Background(), valueAddr(v), valueAddr(src)); err != nil ||
+		!reflect.DeepEqual(v.Interface(), dst.Interface()) {
+		return changed(path, dst, src)
+	}
+
+	return true
+}
+
+// nested reports whether values of the types are structs or pointers to structs, which are compared field by field.
+func (c *Copiers) nested(dst, src reflect.Type) bool {
+	dstElem := indirectType(dst)
+	srcElem := indirectType(src)
+	if dstElem.Kind() != reflect.Struct || srcElem.Kind() != reflect.Struct || c.customCopier(dst, src) != nil {
+		return false
+	}
+
+	if dstElem == srcElem {
+		// Structs without exported fields, such as time.Time, are compared as a whole.
+		return c.cache.GetByType(dstElem).NumField() > 0
+	}
+
+	return valueCopier(dst, src) == nil
+}
+
+// valueConverter returns the copier of values of the types, if the types are not assignable then nil is returned.
+// Converters are prepared once like copiers of structs.
+func (c *Copiers) valueConverter(dst, src reflect.Type) copyFunc {
+	key := copierKey{Src: src, Dest: dst}
+	if copier, ok := c.converters.Peek(key); ok {
+		return copier.(copyFunc)
+	}
+
+	return c.converters.Do(key, func() interface{} {
+		pending := make(map[copierKey]*Copier)
+		copier := c.typeCopier(dst, src, pending)
+		c.publish(pending)
+		return copier
+	}).(copyFunc)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// indirectValue returns the value v points to and false if v is a nil pointer.
+func indirectValue(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() != reflect.Ptr {
+		return v, true
+	}
+	if v.IsNil() {
+		return v, false
+	}
+	return v.Elem(), true
+}
+
+// Equal reports whether the fields of dst are equal to the fields of src, which would be copied to them.
+// Dst and src each must be a struct or a pointer to struct. Fields are matched by the same rules as copying,
+// values of src are converted to types of dst before comparing.
+//
+//   if !c.Equal(&dto, &model) {
+//       t.Error("dto does not reflect model")
+//   }
+func (c *Copiers) Equal(dst, src interface{}) bool {
+	return c.compare(structValue(dst, "destination"), structValue(src, "source"), "", make(map[comparison]bool),
+		func(string, reflect.Value, reflect.Value) bool { return false })
+}
+
+// Equal reports whether the fields of dst are equal to the fields of src, which would be copied to them.
+// Dst and src each must be a struct or a pointer to struct of the types of the Copier.
+func (c Copier) Equal(dst, src interface{}) bool {
+	dstValue := structValue(dst, "destination")
+	srcValue := structValue(src, "source")
+	if dstValue.Type() != c.dst || srcValue.Type() != c.src {
+		panic(fmt.Errorf("copier of «%s» to «%s» can not compare «%s» to «%s»", c.src, c.dst, srcValue.Type(), dstValue.Type()))
+	}
+
+	return c.owner.compare(dstValue, srcValue, "", make(map[comparison]bool),
+		func(string, reflect.Value, reflect.Value) bool { return false })
+}
+
+// Equal reports whether the fields of dst are equal to the fields of src, which would be copied to them.
+func Equal(dst, src interface{}) bool {
+	return defaultCopier.Equal(dst, src)
+}
@@ -0,0 +1,136 @@
+package copy
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCopiers_Diff(t *testing.T) {
+	type address struct {
+		City   string
+		Street string
+	}
+
+	type row struct {
+		Name      string
+		Age       int8
+		Address   address
+		Billing   *address
+		UpdatedAt time.Time
+		Internal  string
+	}
+
+	type dto struct {
+		Name      string
+		Age       int
+		Address   *address
+		Billing   *address
+		UpdatedAt time.Time
+	}
+
+	now := time.Date(2021, 2, 18, 16, 0, 1, 0, time.UTC)
+	a := row{Name: "John", Age: 33, Address: address{City: "Paris", Street: "Main"}, UpdatedAt: now, Internal: "x"}
+	b := dto{Name: "John", Age: 34, Address: &address{City: "Berlin", Street: "Main"}, Billing: &address{}, UpdatedAt: now}
+
+	expected := []Change{
+		{Path: "Age", Old: int8(33), New: 34},
+		{Path: "Address.City", Old: "Paris", New: "Berlin"},
+		{Path: "Billing", Old: (*address)(nil), New: &address{}},
+	}
+
+	changes := New().Diff(&a, &b)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, changes)
+	}
+
+	if changes := New().Diff(a, a); len(changes) != 0 {
+		t.Errorf("same values must not differ, got «%+v»", changes)
+	}
+
+	a2 := a
+	a2.UpdatedAt = now.Add(time.Second)
+	changes = New().Diff(a, a2)
+	expected = []Change{{Path: "UpdatedAt", Old: now, New: now.Add(time.Second)}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, changes)
+	}
+}
+
+func TestCopiers_Equal(t *testing.T) {
+	type model struct {
+		Name   string
+		Middle *string
+		Age    int8
+		Note   sql.NullString
+		Extra  int
+	}
+
+	type dto struct {
+		Name   string
+		Middle sql.NullString
+		Age    int
+		Note   *string
+	}
+
+	src := model{Name: "John", Age: 33, Note: sql.NullString{String: "note", Valid: true}, Extra: 1}
+	dst := dto{}
+
+	c := New()
+	if c.Equal(&dst, &src) {
+		t.Error("must not be equal before copying")
+	}
+
+	copier := c.Get(&dst, &src)
+	copier.Copy(&dst, &src)
+	if !c.Equal(&dst, &src) || !copier.Equal(&dst, &src) || !Equal(dst, src) {
+		t.Error("must be equal after copying")
+	}
+
+	dst.Middle = sql.NullString{String: "", Valid: true}
+	if copier.Equal(&dst, &src) {
+		t.Error("valid null string must not be equal to nil pointer")
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on types of other copier")
+			}
+		}()
+		copier.Equal(&src, &dst)
+	}()
+}
+
+func TestCopiers_DiffCycle(t *testing.T) {
+	type node struct {
+		V    int
+		Next *node
+	}
+	type nodeDTO struct {
+		V    int
+		Next *nodeDTO
+	}
+
+	a := &node{V: 1}
+	a.Next = &node{V: 2, Next: a}
+
+	b := &nodeDTO{}
+	New().Copy(b, a)
+	if b.Next == nil || b.Next.Next != b {
+		t.Fatalf("cyclic value must be copied, got «%+v»", b)
+	}
+	if !Equal(b, a) {
+		t.Error("copied cyclic value must be equal to the source")
+	}
+
+	b.Next.V = 3
+	expected := []Change{{Path: "Next.V", Old: 2, New: 3}}
+	if changes := Diff(a, b); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, changes)
+	}
+	if Equal(b, a) {
+		t.Error("changed cyclic value must not be equal to the source")
+	}
+}
@@ -0,0 +1,136 @@
+package copy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// EnumError is reported when a value has no counterpart in a registered enum mapping.
+type EnumError struct {
+	Type  reflect.Type
+	Value interface{}
+}
+
+func (e *EnumError) Error() string {
+	return fmt.Sprintf("value «%v» of enum «%s» is not mapped", e.Value, e.Type)
+}
+
+// enum is a bidirectional mapping between an integer enum type and a string enum type.
+type enum struct {
+	integer reflect.Type
+	str     reflect.Type
+	names   map[int64]string
+	values  map[string]int64
+}
+
+func isInteger(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func newEnum(integer, str reflect.Type) enum {
+	if !isInteger(integer.Kind()) {
+		panic(fmt.Errorf("enum type «%s» is not integer", integer))
+	}
+	if str.Kind() != reflect.String {
+		panic(fmt.Errorf("enum type «%s» is not string", str))
+	}
+
+	return enum{integer: integer, str: str, names: make(map[int64]string), values: make(map[string]int64)}
+}
+
+// Enum registers a bidirectional mapping between an integer enum and a string enum.
+// The table must be a map with integer keys and string values, e.g. map[model.Status]api.Status.
+//
+//   copy.New(copy.Enum(map[Status]string{Active: "active", Blocked: "blocked"}))
+func Enum(table interface{}) Option {
+	v := reflect.ValueOf(table)
+	if v.Kind() != reflect.Map {
+		panic("enum table must be a map")
+	}
+
+	e := newEnum(v.Type().Key(), v.Type().Elem())
+	iter := v.MapRange()
+	for iter.Next() {
+		i := intOf(iter.Key())
+		s := iter.Value().String()
+		e.names[i] = s
+		e.values[s] = i
+	}
+
+	return func(o *Options) {
+		o.enums = append(o.enums, e)
+	}
+}
+
+// ProtoEnum registers a mapping between an integer enum and a string enum using protobuf-style
+// name and value maps. Integer and str are values of the enum types.
+//
+//   copy.New(copy.ProtoEnum(pb.Status(0), model.Status(""), pb.Status_name, pb.Status_value))
+func ProtoEnum(integer, str interface{}, names map[int32]string, values map[string]int32) Option {
+	e := newEnum(reflect.TypeOf(integer), reflect.TypeOf(str))
+	for i, s := range names {
+		e.names[int64(i)] = s
+	}
+	for s, i := range values {
+		e.values[s] = int64(i)
+	}
+
+	return func(o *Options) {
+		o.enums = append(o.enums, e)
+	}
+}
+
+func intOf(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(v.Uint())
+	default:
+		return v.Int()
+	}
+}
+
+func setInt(v reflect.Value, i int64) {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(i))
+	default:
+		v.SetInt(i)
+	}
+}
+
+// enumCopier returns the copier of enum values, if it is not found then nil is returned.
+func (c *Copiers) enumCopier(dst, src reflect.Type) copyFunc {
+	for _, e := range c.options.enums {
+		e := e
+		switch {
+		case src == e.integer && dst == e.str:
+			return func(_ context.Context, dstPtr, srcPtr pointer) error {
+				i := intOf(valueAt(srcPtr, src))
+				s, ok := e.names[i]
+				if !ok {
+					return &EnumError{Type: src, Value: i}
+				}
+				valueAt(dstPtr, dst).SetString(s)
+				return nil
+			}
+		case src == e.str && dst == e.integer:
+			return func(_ context.Context, dstPtr, srcPtr pointer) error {
+				s := valueAt(srcPtr, src).String()
+				i, ok := e.values[s]
+				if !ok {
+					return &EnumError{Type: src, Value: s}
+				}
+				setInt(valueAt(dstPtr, dst), i)
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package copy
+
+import (
+	"errors"
+	"testing"
+)
+
+type testStatus int32
+
+const (
+	testStatusUnknown testStatus = iota
+	testStatusActive
+	testStatusBlocked
+)
+
+type testStatusName string
+
+func TestCopier_Enum(t *testing.T) {
+	type model struct {
+		Status testStatus
+	}
+
+	type dto struct {
+		Status testStatusName
+	}
+
+	c := New(Enum(map[testStatus]testStatusName{
+		testStatusUnknown: "unknown",
+		testStatusActive:  "active",
+		testStatusBlocked: "blocked",
+	}))
+
+	d := dto{}
+	c.Copy(&d, &model{Status: testStatusBlocked})
+	if d.Status != "blocked" {
+		t.Errorf("want «blocked» got «%s»", d.Status)
+	}
+
+	m := model{}
+	c.Copy(&m, &dto{Status: "active"})
+	if m.Status != testStatusActive {
+		t.Errorf("want «%d» got «%d»", testStatusActive, m.Status)
+	}
+
+	func() {
+		defer func() {
+			err, _ := recover().(error)
+			var enumErr *EnumError
+			if !errors.As(err, &enumErr) {
+				t.Errorf("must panic with EnumError on unknown value, got «%v»", err)
+			}
+		}()
+		c.Copy(&m, &dto{Status: "deleted"})
+	}()
+}
+
+func TestCopier_ProtoEnum(t *testing.T) {
+	names := map[int32]string{0: "STATUS_UNKNOWN", 1: "STATUS_ACTIVE"}
+	values := map[string]int32{"STATUS_UNKNOWN": 0, "STATUS_ACTIVE": 1, "ACTIVE": 1}
+
+	type message struct {
+		Status int32
+	}
+
+	type model struct {
+		Status string
+	}
+
+	c := New(ProtoEnum(int32(0), "", names, values))
+
+	m := model{}
+	c.Copy(&m, &message{Status: 1})
+	if m.Status != "STATUS_ACTIVE" {
+		t.Errorf("want «STATUS_ACTIVE» got «%s»", m.Status)
+	}
+
+	msg := message{}
+	c.Copy(&msg, &model{Status: "ACTIVE"})
+	if msg.Status != 1 {
+		t.Errorf("want «1» got «%d»", msg.Status)
+	}
+}
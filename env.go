@@ -0,0 +1,129 @@
+package copy
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// FromEnv fills dst from the environment variables. Dst must be a pointer to struct.
+// Keys are field names joined with the prefix by "_", fields of embedded structs are flattened and
+// fields of nested structs are prefixed by the name of the containing field. Values are parsed
+// by the textfuncs package, slice fields take comma separated values. A nested struct of the type of
+// a struct containing it, such as the next node of a list, is not filled.
+//
+//   type Config struct {
+//       Port int `copy:"PORT"`
+//       DB   struct {
+//           Host string `copy:"HOST"`
+//       } `copy:"DB"`
+//   }
+//   err := c.FromEnv(&config, "APP") // Reads APP_PORT and APP_DB_HOST.
+func (c *Copiers) FromEnv(dst interface{}, prefix string) error {
+	return c.FromLookup(dst, prefix, os.LookupEnv)
+}
+
+// FromEnv fills dst from the environment variables.
+func FromEnv(dst interface{}, prefix string) error {
+	return defaultCopier.FromEnv(dst, prefix)
+}
+
+// FromLookup fills dst from the variables returned by the lookup, such as os.LookupEnv.
+// Keys and values follow the rules of FromEnv.
+//
+//   env := map[string]string{"APP_PORT": "8080"}
+//   err := c.FromLookup(&config, "APP", func(key string) (string, bool) { v, ok := env[key]; return v, ok })
+func (c *Copiers) FromLookup(dst interface{}, prefix string, lookup func(key string) (string, bool)) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("destination must be pointer to struct")
+	}
+
+	_, err := c.fromLookup(v.Elem(), prefix, lookup, map[reflect.Type]bool{v.Elem().Type(): true})
+	return err
+}
+
+// FromLookup fills dst from the variables returned by the lookup.
+func FromLookup(dst interface{}, prefix string, lookup func(key string) (string, bool)) error {
+	return defaultCopier.FromLookup(dst, prefix, lookup)
+}
+
+// fromLookup fills the struct value and reports whether any variable is found. Structs on the way to the struct
+// are visiting, nested structs of their types are not filled to avoid endless recursion of recursive types.
+func (c *Copiers) fromLookup(v reflect.Value, prefix string, lookup func(key string) (string, bool),
+	visiting map[reflect.Type]bool) (bool, error) {
+	found := false
+
+	s := c.cache.GetByType(v.Type())
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if f.Anonymous || f.Options.ReadOnly || c.ignored(f.Name) {
+			continue
+		}
+
+		key := f.Name
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		if c.mapStruct(f.Type) {
+			elem := indirectType(f.Type)
+			if visiting[elem] {
+				continue
+			}
+
+			// Nil embedded pointers on the way to the field are allocated only if any variable is found.
+			fv, ok := fieldValue(v, f)
+			if !ok {
+				fv = reflect.New(f.Type).Elem()
+			}
+			visiting[elem] = true
+			structFound, err := c.lookupStruct(fv, key, lookup, visiting)
+			delete(visiting, elem)
+			if err != nil {
+				return false, err
+			}
+			if structFound && !ok {
+				fieldValueAlloc(v, f).Set(fv)
+			}
+			found = found || structFound
+			continue
+		}
+
+		value, ok := lookup(key)
+		if !ok {
+			continue
+		}
+		found = true
+
+		values := []string{value}
+		if textSlice(f.Type) {
+			values = strings.Split(value, ",")
+		}
+		if err := c.parseField(fieldValueAlloc(v, f), values); err != nil {
+			return false, fmt.Errorf("variable «%s»: %w", key, err)
+		}
+	}
+
+	return found, nil
+}
+
+// lookupStruct fills the nested struct or the pointer to struct, the pointer is allocated only if any variable is found.
+func (c *Copiers) lookupStruct(v reflect.Value, prefix string, lookup func(key string) (string, bool),
+	visiting map[reflect.Type]bool) (bool, error) {
+	if v.Kind() != reflect.Ptr {
+		return c.fromLookup(v, prefix, lookup, visiting)
+	}
+
+	if !v.IsNil() {
+		return c.fromLookup(v.Elem(), prefix, lookup, visiting)
+	}
+
+	p := reflect.New(v.Type().Elem())
+	found, err := c.fromLookup(p.Elem(), prefix, lookup, visiting)
+	if found && err == nil {
+		v.Set(p)
+	}
+	return found, err
+}
@@ -0,0 +1,102 @@
+package copy
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testEnvDB struct {
+	Host string `copy:"HOST"`
+	Port int    `copy:"PORT"`
+}
+
+type testEnvConfig struct {
+	MapBase
+	Debug   bool          `copy:"DEBUG"`
+	Timeout time.Duration `copy:"TIMEOUT"`
+	Hosts   []string      `copy:"HOSTS"`
+	DB      testEnvDB
+	Cache   *testEnvDB `copy:"CACHE"`
+	Replica *testEnvDB `copy:"REPLICA"`
+}
+
+func TestCopiers_FromLookup(t *testing.T) {
+	env := map[string]string{
+		"APP_ID":         "3",
+		"APP_DEBUG":      "true",
+		"APP_TIMEOUT":    "5s",
+		"APP_HOSTS":      "a,b",
+		"APP_DB_HOST":    "db",
+		"APP_DB_PORT":    "5432",
+		"APP_CACHE_HOST": "cache",
+		"DEBUG":          "false",
+	}
+	lookup := func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+
+	c := New(Tag("copy"))
+	config := testEnvConfig{}
+	if err := c.FromLookup(&config, "APP", lookup); err != nil {
+		t.Fatalf("from lookup: %s", err)
+	}
+
+	expected := testEnvConfig{
+		MapBase: MapBase{ID: 3},
+		Debug:   true,
+		Timeout: 5 * time.Second,
+		Hosts:   []string{"a", "b"},
+		DB:      testEnvDB{Host: "db", Port: 5432},
+		Cache:   &testEnvDB{Host: "cache"},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, config)
+	}
+
+	env["APP_DB_PORT"] = "port"
+	if err := c.FromLookup(&config, "APP", lookup); err == nil {
+		t.Error("must fail on invalid value")
+	}
+}
+
+func TestCopiers_FromLookupRecursive(t *testing.T) {
+	type Node struct {
+		Name string
+		Next *Node
+	}
+
+	var keys []string
+	node := Node{}
+	err := New().FromLookup(&node, "APP", func(key string) (string, bool) {
+		keys = append(keys, key)
+		return "name", true
+	})
+	if err != nil {
+		t.Fatalf("from lookup: %s", err)
+	}
+	if node.Name != "name" || node.Next != nil {
+		t.Errorf("want «name» without the next node got «%+v»", node)
+	}
+	if want := []string{"APP_Name"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("want «%v» got «%v»", want, keys)
+	}
+}
+
+func TestCopiers_FromEnv(t *testing.T) {
+	const key = "COPY_TEST_DB_HOST"
+	if err := os.Setenv(key, "localhost"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(key)
+
+	config := testEnvConfig{}
+	if err := New(Tag("copy")).FromEnv(&config, "COPY_TEST"); err != nil {
+		t.Fatalf("from env: %s", err)
+	}
+	if config.DB.Host != "localhost" {
+		t.Errorf("want «localhost» got «%s»", config.DB.Host)
+	}
+}
@@ -1,11 +1,13 @@
+// Package funcs provides copy functions for specified types.
+//
 //go:generate go run gen.go
 //go:generate gofmt -s -w funcs.gen.go
-// Package funcs provides copy functions for specified types.
 package funcs
 
 import (
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 )
 
@@ -24,17 +26,25 @@ func typeOfPointer(v interface{}) reflect.Type {
 }
 
 // CopyFuncs is the storage of functions intended for copying data.
+// Functions are read without locks, Set copies the map of functions.
 type CopyFuncs struct {
-	mu    sync.RWMutex
-	funcs map[funcKey]func(dst, src unsafe.Pointer)
+	mu    sync.Mutex                                // Serializes Set.
+	funcs map[funcKey]func(dst, src unsafe.Pointer) // Initial functions, the map is not modified.
+	set   atomic.Value                              // Copy of funcs with the functions added by Set.
 	sizes []func(dst, src unsafe.Pointer)
 }
 
+// current returns the map of the functions.
+func (t *CopyFuncs) current() map[funcKey]func(dst, src unsafe.Pointer) {
+	if funcs, ok := t.set.Load().(map[funcKey]func(dst, src unsafe.Pointer)); ok {
+		return funcs
+	}
+	return t.funcs
+}
+
 // Get the copy function for the pair of types, if it is not found then nil is returned.
 func (t *CopyFuncs) Get(dst, src reflect.Type) func(dst, src unsafe.Pointer) {
-	t.mu.RLock()
-	f := t.funcs[funcKey{Src: src, Dst: dst}]
-	t.mu.RUnlock()
+	f := t.current()[funcKey{Src: src, Dst: dst}]
 	if f != nil {
 		return f
 	}
@@ -51,8 +61,11 @@ func (t *CopyFuncs) Get(dst, src reflect.Type) func(dst, src unsafe.Pointer) {
 	same := dst == src
 
 	switch dst.Kind() {
-	case reflect.Array, reflect.Chan, reflect.Ptr, reflect.Slice:
+	case reflect.Array, reflect.Ptr, reflect.Slice:
 		same = same || dst.Elem() == src.Elem()
+	case reflect.Chan:
+		// The source channel must allow every direction of the destination channel.
+		same = same || (dst.Elem() == src.Elem() && src.ChanDir()&dst.ChanDir() == dst.ChanDir())
 	case reflect.Map:
 		same = same || (dst.Elem() == src.Elem() && dst.Key() == src.Key())
 	}
@@ -67,8 +80,15 @@ func (t *CopyFuncs) Get(dst, src reflect.Type) func(dst, src unsafe.Pointer) {
 // Set the copy function for the pair of types.
 func (t *CopyFuncs) Set(dst, src reflect.Type, f func(dst, src unsafe.Pointer)) {
 	t.mu.Lock()
-	t.funcs[funcKey{Src: src, Dst: dst}] = f
-	t.mu.Unlock()
+	defer t.mu.Unlock()
+
+	current := t.current()
+	m := make(map[funcKey]func(dst, src unsafe.Pointer), len(current)+1)
+	for key, fn := range current {
+		m[key] = fn
+	}
+	m[funcKey{Src: src, Dst: dst}] = f
+	t.set.Store(m)
 }
 
 // Get the copy function for the pair of types, if it is not found then nil is returned.
@@ -76,8 +96,12 @@ func Get(dst, src reflect.Type) func(dst, src unsafe.Pointer) {
 	return funcs.Get(dst, src)
 }
 
-// Set the copy function for the pair of types.
+// Set the copy function for the pair of types. The copy package built with the copy_safe tag does not use
+// copy functions, so Set panics in this case, converters registered by copy.Converter should be used instead.
 func Set(dst, src reflect.Type, f func(dst, src unsafe.Pointer)) {
+	if safe {
+		panic("copy functions are not supported by the safe mode, use converters of the copy package")
+	}
 	funcs.Set(dst, src, f)
 }
 
@@ -238,6 +238,13 @@ func TestTypesCopiersNil(t *testing.T) {
 }
 
 func TestSet(t *testing.T) {
+	if safe {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic in the safe mode")
+			}
+		}()
+	}
 	Set(reflect.TypeOf(int(0)), reflect.TypeOf(int(0)), func(dst, src unsafe.Pointer) {
 		*(*int)(unsafe.Pointer(dst)) = int(*(*int)(unsafe.Pointer(src)))
 	})
@@ -254,3 +261,25 @@ func TestGet(t *testing.T) {
 		t.Error("Get(map, map) should not return nil")
 	}
 }
+
+func TestGetChan(t *testing.T) {
+	if Get(reflect.TypeOf(make(<-chan int)), reflect.TypeOf(make(chan int))) == nil {
+		t.Error("Get(<-chan, chan) should not return nil")
+	}
+
+	if Get(reflect.TypeOf(make(chan int)), reflect.TypeOf(make(<-chan int))) != nil {
+		t.Error("Get(chan, <-chan) should return nil")
+	}
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	typ := reflect.TypeOf(int(0))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if Get(typ, typ) == nil {
+				b.Fatal("copy function of int must be registered")
+			}
+		}
+	})
+}
@@ -0,0 +1,7 @@
+//go:build copy_safe
+// +build copy_safe
+
+package funcs
+
+// safe reports whether the copy package is built in the safe mode, that does not use copy functions.
+const safe = true
@@ -2,4 +2,7 @@ module github.com/gotidy/copy
 
 go 1.15
 
-require github.com/gotidy/ptr v1.3.0
+require (
+	github.com/gotidy/ptr v1.3.0
+	google.golang.org/protobuf v1.26.0
+)
@@ -0,0 +1,225 @@
+// Package lru provides the concurrent cache evicting the least recently used entries.
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats is statistics of the cache.
+type Stats struct {
+	Hits      uint64 // Number of lookups of cached entries.
+	Misses    uint64 // Number of lookups of missing entries.
+	Evictions uint64 // Number of evicted entries.
+	Entries   int    // Number of cached entries.
+}
+
+type entry struct {
+	key, value interface{}
+}
+
+// Cache is the concurrent cache. If the maximum number of entries is not positive, then entries are never evicted
+// and lookups are lock-free.
+type Cache struct {
+	// 64-bit words are accessed atomically, they are first to be aligned on 32-bit platforms.
+	hits, misses, evictions uint64
+	entries                 int64
+
+	max int
+
+	// Unlimited cache.
+	values sync.Map
+
+	// Limited cache.
+	mu    sync.Mutex
+	items map[interface{}]*list.Element
+	order *list.List // Front is the most recently used entry.
+
+	flightsMu sync.Mutex
+	flights   map[interface{}]*flight // Values being created by Do.
+}
+
+// flight is a value being created, calls waiting for it are released by closing done.
+type flight struct {
+	done  chan struct{}
+	value interface{}
+	ok    bool
+}
+
+// New creates the cache with the maximum number of entries.
+func New(max int) *Cache {
+	return &Cache{max: max, items: make(map[interface{}]*list.Element), order: list.New()}
+}
+
+// Get returns the value of the key and false if it is not found.
+func (c *Cache) Get(key interface{}) (interface{}, bool) {
+	value, ok := c.Peek(key)
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return value, ok
+}
+
+// Lookup returns the value of the key and false if it is not found, only hits are counted. It is meant for
+// the fast path followed by Do, so misses are counted once per creation.
+func (c *Cache) Lookup(key interface{}) (interface{}, bool) {
+	value, ok := c.Peek(key)
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	}
+	return value, ok
+}
+
+// Peek returns the value of the key and false if it is not found, statistics are not changed.
+func (c *Cache) Peek(key interface{}) (interface{}, bool) {
+	if c.max <= 0 {
+		return c.values.Load(key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*entry).value, true
+}
+
+// Do returns the value of the key, the missing value is created by create and added. Concurrent calls for the same
+// missing key wait for the one creating the value, so it is created once, values of different keys are created
+// in parallel. If create panics, waiting calls create the value again. Misses are counted once per creation,
+// hits are not counted, Do is meant to follow Lookup.
+func (c *Cache) Do(key interface{}, create func() interface{}) interface{} {
+	for {
+		c.flightsMu.Lock()
+		if value, ok := c.Peek(key); ok {
+			c.flightsMu.Unlock()
+			return value
+		}
+		if f, ok := c.flights[key]; ok {
+			c.flightsMu.Unlock()
+			<-f.done
+			if f.ok {
+				return f.value
+			}
+			continue
+		}
+
+		f := &flight{done: make(chan struct{})}
+		if c.flights == nil {
+			c.flights = make(map[interface{}]*flight)
+		}
+		c.flights[key] = f
+		c.flightsMu.Unlock()
+		atomic.AddUint64(&c.misses, 1)
+
+		return c.fly(key, f, create)
+	}
+}
+
+// fly creates the value of the flight and releases calls waiting for it.
+func (c *Cache) fly(key interface{}, f *flight, create func() interface{}) interface{} {
+	defer func() {
+		c.flightsMu.Lock()
+		delete(c.flights, key)
+		c.flightsMu.Unlock()
+		close(f.done)
+	}()
+
+	f.value = create()
+	c.Add(key, f.value)
+	f.ok = true
+
+	return f.value
+}
+
+// Add adds the value of the key, evicting the least recently used entries if the cache is full.
+func (c *Cache) Add(key, value interface{}) {
+	if c.max <= 0 {
+		c.mu.Lock()
+		if _, loaded := c.values.LoadOrStore(key, value); loaded {
+			c.values.Store(key, value)
+		} else {
+			atomic.AddInt64(&c.entries, 1)
+		}
+		c.mu.Unlock()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value})
+	for c.order.Len() > c.max {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+// Remove removes the key.
+func (c *Cache) Remove(key interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.max <= 0 {
+		if _, loaded := c.values.LoadAndDelete(key); loaded {
+			atomic.AddInt64(&c.entries, -1)
+		}
+		return
+	}
+
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+func (c *Cache) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.items, e.Value.(*entry).key)
+}
+
+// Reset removes all entries and resets statistics.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values.Range(func(key, _ interface{}) bool {
+		c.values.Delete(key)
+		return true
+	})
+	atomic.StoreInt64(&c.entries, 0)
+	c.items = make(map[interface{}]*list.Element)
+	c.order.Init()
+	atomic.StoreUint64(&c.hits, 0)
+	atomic.StoreUint64(&c.misses, 0)
+	c.evictions = 0
+}
+
+// Stats returns statistics of the cache.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := int(atomic.LoadInt64(&c.entries))
+	if c.max > 0 {
+		entries = len(c.items)
+	}
+
+	return Stats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: c.evictions,
+		Entries:   entries,
+	}
+}
@@ -0,0 +1,97 @@
+package lru
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	c := New(2)
+	c.Add(1, "a")
+	c.Add(2, "b")
+	c.Get(1) // 2 becomes the least recently used.
+	c.Add(3, "c")
+
+	if _, ok := c.Peek(2); ok {
+		t.Error("least recently used entry must be evicted")
+	}
+	if v, ok := c.Peek(1); !ok || v != "a" {
+		t.Errorf("want «a» got «%v»", v)
+	}
+
+	want := Stats{Hits: 1, Evictions: 1, Entries: 2}
+	if got := c.Stats(); got != want {
+		t.Errorf("want «%+v» got «%+v»", want, got)
+	}
+
+	c.Remove(1)
+	if _, ok := c.Get(1); ok {
+		t.Error("removed entry must not be found")
+	}
+
+	c.Reset()
+	if got := c.Stats(); got != (Stats{}) {
+		t.Errorf("want empty stats got «%+v»", got)
+	}
+}
+
+func TestCache_Unlimited(t *testing.T) {
+	c := New(0)
+	for i := 0; i < 100; i++ {
+		c.Add(i, i)
+	}
+	if got := c.Stats(); got.Entries != 100 || got.Evictions != 0 {
+		t.Errorf("want «100» entries without evictions got «%+v»", got)
+	}
+}
+
+func TestCache_Do(t *testing.T) {
+	c := New(0)
+
+	// Concurrent calls for the same key create the value once.
+	release := make(chan struct{})
+	var created int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v := c.Do(1, func() interface{} {
+				atomic.AddInt32(&created, 1)
+				<-release
+				return "a"
+			})
+			if v != "a" {
+				t.Errorf("want «a» got «%v»", v)
+			}
+		}()
+	}
+
+	// Values of other keys are created while the value of the key is being created.
+	if v := c.Do(2, func() interface{} { return "b" }); v != "b" {
+		t.Errorf("want «b» got «%v»", v)
+	}
+	close(release)
+	wg.Wait()
+
+	if created != 1 {
+		t.Errorf("want the value created once got «%d» times", created)
+	}
+	if got := c.Stats(); got.Misses != 2 || got.Entries != 2 {
+		t.Errorf("want «2» misses and «2» entries got «%+v»", got)
+	}
+
+	// The value is created again, if creating panics.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic of create must be propagated")
+			}
+		}()
+		c.Do(3, func() interface{} { panic("failed") })
+	}()
+	if v := c.Do(3, func() interface{} { return "c" }); v != "c" {
+		t.Errorf("want «c» got «%v»", v)
+	}
+}
@@ -0,0 +1,241 @@
+package copy
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var (
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ToMap returns the map of the src fields. Src must be a struct or a pointer to struct.
+// Keys are field names, fields of embedded structs are flattened, nested structs become nested maps.
+// Structs without exported fields, such as time.Time, and structs implementing driver.Valuer
+// or encoding.TextMarshaler are kept as is.
+// Pointers to structs already on the path, such as back references of cyclic values, are omitted.
+//
+//   m := c.ToMap(&user) // map[string]interface{}{"Name": "John", "Address": map[string]interface{}{"City": "Paris"}}
+//
+// Fields are read the way copying reads them: empty fields with omitempty are omitted, masks are applied
+// and fields with roles are omitted, as no principal is passed. Use ToMapContext to pass the principal.
+func (c *Copiers) ToMap(src interface{}) map[string]interface{} {
+	return c.ToMapContext(context.Background(), src)
+}
+
+// ToMap returns the map of the src fields.
+func ToMap(src interface{}) map[string]interface{} {
+	return defaultCopier.ToMap(src)
+}
+
+// ToMapContext returns the map of the src fields, fields with roles are read if the principal
+// carried by the context has one of the roles.
+//
+//   m := c.ToMapContext(copy.WithPrincipal(ctx, copy.Roles{"admin"}), &user)
+func (c *Copiers) ToMapContext(ctx context.Context, src interface{}) map[string]interface{} {
+	return c.toMap(ctx, structValue(src, "source"), make(map[structAddr]bool))
+}
+
+// ToMapContext returns the map of the src fields.
+func ToMapContext(ctx context.Context, src interface{}) map[string]interface{} {
+	return defaultCopier.ToMapContext(ctx, src)
+}
+
+// structAddr is the address of a struct of the type.
+type structAddr struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// addrOfStruct returns the address of the struct value and false if it is not addressable.
+func addrOfStruct(v reflect.Value) (structAddr, bool) {
+	if !v.CanAddr() {
+		return structAddr{}, false
+	}
+	return structAddr{addr: v.UnsafeAddr(), typ: v.Type()}, true
+}
+
+// toMap returns the map of the struct fields. Nested structs already on the path are omitted,
+// so cyclic values are not traversed forever.
+func (c *Copiers) toMap(ctx context.Context, v reflect.Value, visiting map[structAddr]bool) map[string]interface{} {
+	if addr, ok := addrOfStruct(v); ok {
+		visiting[addr] = true
+		defer delete(visiting, addr)
+	}
+
+	s := c.cache.GetByType(v.Type())
+	m := make(map[string]interface{}, s.NumField())
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if f.Anonymous || f.Options.WriteOnly || c.ignored(f.Name) {
+			continue
+		}
+
+		fv, ok := c.readValue(ctx, v, f)
+		if !ok {
+			continue
+		}
+		if !c.mapStruct(f.Type) {
+			m[f.Name] = fv.Interface()
+			continue
+		}
+
+		fv, ok = indirectValue(fv)
+		if !ok {
+			m[f.Name] = nil
+			continue
+		}
+		if addr, ok := addrOfStruct(fv); ok && visiting[addr] {
+			continue
+		}
+		m[f.Name] = c.toMap(ctx, fv, visiting)
+	}
+
+	return m
+}
+
+// FromMap fills dst from the map. Dst must be a pointer to struct.
+// Keys are field names, fields of embedded structs are flattened, nested maps fill nested structs.
+// Values are converted to field types by the same rules as copying, e.g. JSON numbers fill integer fields.
+//
+//   var m map[string]interface{}
+//   _ = json.Unmarshal(data, &m)
+//   err := c.FromMap(&user, m)
+func (c *Copiers) FromMap(dst interface{}, m map[string]interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("destination must be pointer to struct")
+	}
+
+	return c.fromMap(v.Elem(), m)
+}
+
+// FromMap fills dst from the map.
+func FromMap(dst interface{}, m map[string]interface{}) error {
+	return defaultCopier.FromMap(dst, m)
+}
+
+func (c *Copiers) fromMap(v reflect.Value, m map[string]interface{}) error {
+	s := c.cache.GetByType(v.Type())
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if f.Anonymous || f.Options.ReadOnly || c.ignored(f.Name) {
+			continue
+		}
+
+		value, ok := m[f.Name]
+		if !ok {
+			continue
+		}
+
+		if err := c.setValue(fieldValueAlloc(v, f), value); err != nil {
+			return fmt.Errorf("field «%s»: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// setValue sets the value converted to the destination type.
+func (c *Copiers) setValue(dst reflect.Value, value interface{}) error {
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if m, ok := value.(map[string]interface{}); ok && c.mapStruct(dst.Type()) {
+		if dst.Kind() == reflect.Ptr {
+			if dst.IsNil() {
+				dst.Set(reflect.New(dst.Type().Elem()))
+			}
+			dst = dst.Elem()
+		}
+		return c.fromMap(dst, m)
+	}
+
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	// JSON arrays are decoded as []interface{}.
+	if items, ok := value.([]interface{}); ok && dst.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(dst.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := c.setValue(slice.Index(i), item); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		dst.Set(slice)
+		return nil
+	}
+
+	// JSON numbers are decoded as float64.
+	if src.Kind() == reflect.Float32 || src.Kind() == reflect.Float64 {
+		if ok, err := setInteger(dst, src.Float()); ok || err != nil {
+			return err
+		}
+	}
+
+	copier := c.valueConverter(dst.Type(), src.Type())
+	if copier == nil {
+		return fmt.Errorf("value of type «%s» is not assignable to type «%s»", src.Type(), dst.Type())
+	}
+
+	srcValue := reflect.New(src.Type()).Elem()
+	srcValue.Set(src)
+
+	return copier(context.Background(), valueAddr(dst), valueAddr(srcValue))
+}
+
+// setInteger sets the integer or the pointer to integer to the float value, that must be integral and in range.
+// It returns false if the destination is not an integer.
+func setInteger(dst reflect.Value, f float64) (bool, error) {
+	t := indirectType(dst.Type())
+
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || v.OverflowInt(int64(f)) {
+			return true, fmt.Errorf("value %v is not representable by type «%s»", f, t)
+		}
+		v.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || v.OverflowUint(uint64(f)) {
+			return true, fmt.Errorf("value %v is not representable by type «%s»", f, t)
+		}
+		v.SetUint(uint64(f))
+	default:
+		return false, nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		dst.Set(v.Addr())
+	} else {
+		dst.Set(v)
+	}
+	return true, nil
+}
+
+// mapStruct reports whether values of the type are structs or pointers to structs, that are represented by maps.
+func (c *Copiers) mapStruct(t reflect.Type) bool {
+	elem := indirectType(t)
+	if elem.Kind() != reflect.Struct {
+		return false
+	}
+
+	ptr := reflect.PtrTo(elem)
+	if ptr.Implements(valuerType) || ptr.Implements(textMarshalerType) {
+		return false
+	}
+
+	return c.cache.GetByType(elem).NumField() > 0
+}
@@ -0,0 +1,143 @@
+package copy
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type MapBase struct {
+	ID int64
+}
+
+type testMapAddress struct {
+	City string
+	Zip  *int
+}
+
+type testMapUser struct {
+	MapBase
+	Name      string `copy:"name"`
+	Age       int
+	Tags      []string
+	Scores    []int
+	Address   testMapAddress
+	Billing   *testMapAddress
+	Nick      sql.NullString
+	CreatedAt time.Time
+}
+
+func TestCopiers_ToMap(t *testing.T) {
+	created := time.Date(2021, 2, 18, 16, 0, 1, 0, time.UTC)
+	user := testMapUser{
+		MapBase:   MapBase{ID: 1},
+		Name:      "John",
+		Age:       33,
+		Address:   testMapAddress{City: "Paris"},
+		Nick:      sql.NullString{String: "j", Valid: true},
+		CreatedAt: created,
+	}
+
+	expected := map[string]interface{}{
+		"ID":        int64(1),
+		"name":      "John",
+		"Age":       33,
+		"Tags":      []string(nil),
+		"Scores":    []int(nil),
+		"Address":   map[string]interface{}{"City": "Paris", "Zip": (*int)(nil)},
+		"Billing":   nil,
+		"Nick":      sql.NullString{String: "j", Valid: true},
+		"CreatedAt": created,
+	}
+
+	if m := New(Tag("copy")).ToMap(&user); !reflect.DeepEqual(m, expected) {
+		t.Errorf("want «%#v» got «%#v»", expected, m)
+	}
+}
+
+func TestCopiers_FromMap(t *testing.T) {
+	data := `{
+		"ID": 1,
+		"name": "John",
+		"Age": 33,
+		"Tags": ["a", "b"],
+		"Scores": [1, 2],
+		"Address": {"City": "Paris", "Zip": 75001},
+		"Billing": {"City": "Berlin"},
+		"Nick": "j"
+	}`
+
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("unmarshal: %s", err)
+	}
+
+	c := New(Tag("copy"))
+	user := testMapUser{}
+	if err := c.FromMap(&user, m); err != nil {
+		t.Fatalf("from map: %s", err)
+	}
+
+	zip := 75001
+	expected := testMapUser{
+		MapBase: MapBase{ID: 1},
+		Name:    "John",
+		Age:     33,
+		Tags:    []string{"a", "b"},
+		Scores:  []int{1, 2},
+		Address: testMapAddress{City: "Paris", Zip: &zip},
+		Billing: &testMapAddress{City: "Berlin"},
+		Nick:    sql.NullString{String: "j", Valid: true},
+	}
+	if !reflect.DeepEqual(user, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, user)
+	}
+
+	if err := c.FromMap(&user, map[string]interface{}{"Age": "old"}); err == nil {
+		t.Error("must fail on not assignable value")
+	}
+	for _, age := range []float64{33.5, 1e20, -1e20} {
+		if err := c.FromMap(&user, map[string]interface{}{"Age": age}); err == nil {
+			t.Errorf("must fail on not integral or out of range value «%v»", age)
+		}
+	}
+
+	back := testMapUser{}
+	if err := c.FromMap(&back, c.ToMap(&user)); err != nil {
+		t.Fatalf("from map: %s", err)
+	}
+	if !reflect.DeepEqual(back, user) {
+		t.Errorf("want «%+v» got «%+v»", user, back)
+	}
+}
+
+func TestCopiers_ToMapCycle(t *testing.T) {
+	type node struct {
+		V    int
+		Next *node
+	}
+
+	a := &node{V: 1}
+	a.Next = &node{V: 2, Next: a}
+
+	// The back reference to the struct on the path is omitted.
+	expected := map[string]interface{}{"V": 1, "Next": map[string]interface{}{"V": 2}}
+	if m := ToMap(a); !reflect.DeepEqual(m, expected) {
+		t.Errorf("want «%v» got «%v»", expected, m)
+	}
+
+	// Structs referenced twice but not on the same path are kept.
+	shared := &node{V: 3}
+	type pair struct {
+		A, B *node
+	}
+	expected = map[string]interface{}{
+		"A": map[string]interface{}{"V": 3, "Next": nil},
+		"B": map[string]interface{}{"V": 3, "Next": nil},
+	}
+	if m := ToMap(&pair{A: shared, B: shared}); !reflect.DeepEqual(m, expected) {
+		t.Errorf("want «%v» got «%v»", expected, m)
+	}
+}
@@ -0,0 +1,121 @@
+package copy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// mapping is the configuration of copying of a specific destination and source pair.
+type mapping struct {
+	conditions map[string][]reflect.Value
+	defaults   map[string]reflect.Value
+}
+
+func (m *mapping) defaultValue(field string) (reflect.Value, bool) {
+	if m == nil {
+		return reflect.Value{}, false
+	}
+	v, ok := m.defaults[field]
+	return v, ok
+}
+
+// MappingOption configures copying of a specific destination and source pair.
+type MappingOption func(m *mapping)
+
+// When copies the field only when the predicate on the source holds.
+// The predicate must be of the form func(src *S) bool, where S is the source struct.
+//
+//   c.Map(&OrderDTO{}, &Order{}, copy.When("Discount", func(src *Order) bool { return src.IsPremium }))
+func When(field string, predicate interface{}) MappingOption {
+	return func(m *mapping) {
+		if m.conditions == nil {
+			m.conditions = make(map[string][]reflect.Value)
+		}
+		m.conditions[field] = append(m.conditions[field], reflect.ValueOf(predicate))
+	}
+}
+
+// Map configures copying of the destination and source pair. Dst and src each must be a struct or a pointer to struct.
+// Field names are names the fields are matched by. It panics if the copier for the pair is already prepared.
+//
+//   c := copy.New()
+//   c.Map(&OrderDTO{}, &Order{}, copy.When("Discount", func(src *Order) bool { return src.IsPremium }))
+func (c *Copiers) Map(dst, src interface{}, options ...MappingOption) {
+	srcType := reflect.Indirect(reflect.ValueOf(src)).Type()
+	if srcType.Kind() != reflect.Struct {
+		panic("source must be struct")
+	}
+
+	dstType := reflect.Indirect(reflect.ValueOf(dst)).Type()
+	if dstType.Kind() != reflect.Struct {
+		panic("destination must be struct")
+	}
+
+	key := copierKey{Src: srcType, Dest: dstType}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.copiers.Peek(key); ok {
+		panic(fmt.Errorf("copier of «%s» to «%s» is already prepared", srcType, dstType))
+	}
+
+	m := c.mappings[key]
+	if m == nil {
+		m = &mapping{}
+	}
+	for _, option := range options {
+		option(m)
+	}
+
+	srcStruct := c.cache.GetByType(srcType)
+	predicateType := reflect.FuncOf([]reflect.Type{reflect.PtrTo(srcType)}, []reflect.Type{reflect.TypeOf(false)}, false)
+	for field, predicates := range m.conditions {
+		if _, ok := srcStruct.FieldByName(field); !ok {
+			panic(fmt.Errorf("source «%s» has no field «%s»", srcType, field))
+		}
+		for _, p := range predicates {
+			if p.Type() != predicateType {
+				panic(fmt.Errorf("predicate of field «%s» must be of type «%s», got «%s»", field, predicateType, p.Type()))
+			}
+		}
+	}
+
+	dstStruct := c.cache.GetByType(dstType)
+	for field, value := range m.defaults {
+		f, ok := dstStruct.FieldByName(field)
+		if !ok {
+			panic(fmt.Errorf("destination «%s» has no field «%s»", dstType, field))
+		}
+		if _, err := defaultSetter(f.Type, value); err != nil {
+			panic(fmt.Errorf("default value of field «%s»: %w", field, err))
+		}
+	}
+
+	if c.mappings == nil {
+		c.mappings = make(map[copierKey]*mapping)
+	}
+	c.mappings[key] = m
+}
+
+func (c *Copiers) mapping(dst, src reflect.Type) *mapping {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.mappings[copierKey{Src: src, Dest: dst}]
+}
+
+// conditionCopier returns the copier, that copies only when the predicates on the source hold.
+func conditionCopier(copier fieldCopier, src reflect.Type, predicates []reflect.Value) fieldCopier {
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		args := []reflect.Value{addrOf(srcPtr, src)}
+		for _, p := range predicates {
+			if !p.Call(args)[0].Bool() {
+				return nil
+			}
+		}
+
+		return copier(ctx, dstPtr, srcPtr)
+	}
+}
@@ -0,0 +1,128 @@
+package copy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+)
+
+func TestCopiers_When(t *testing.T) {
+	type Base struct {
+		Note string
+	}
+
+	type order struct {
+		Base
+		IsPremium bool
+		Discount  int
+		Total     int
+	}
+
+	type orderDTO struct {
+		Base
+		Discount int
+		Total    int
+	}
+
+	c := New()
+	c.Map(&orderDTO{}, &order{},
+		When("Discount", func(src *order) bool { return src.IsPremium }),
+		When("Note", func(src *order) bool { return src.IsPremium }),
+	)
+
+	dst := orderDTO{}
+	c.Copy(&dst, &order{Base: Base{Note: "note"}, Discount: 10, Total: 100})
+	equal(t, dst, orderDTO{Total: 100})
+
+	dst = orderDTO{}
+	c.Copy(&dst, &order{Base: Base{Note: "note"}, IsPremium: true, Discount: 10, Total: 100})
+	equal(t, dst, orderDTO{Base: Base{Note: "note"}, Discount: 10, Total: 100})
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic when the copier is already prepared")
+			}
+		}()
+		c.Map(&orderDTO{}, &order{}, When("Total", func(src *order) bool { return true }))
+	}()
+}
+
+func TestCopiers_WhenInvalid(t *testing.T) {
+	type testStruct struct {
+		I int
+	}
+
+	for _, option := range []MappingOption{
+		When("I", func(src testStruct) bool { return true }),
+		When("I", func(src *testStruct) {}),
+		When("J", func(src *testStruct) bool { return true }),
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("must panic on invalid condition")
+				}
+			}()
+			New().Map(&testStruct{}, &testStruct{}, option)
+		}()
+	}
+}
+
+func TestCopiers_Default(t *testing.T) {
+	type modelV1 struct {
+		Name  string
+		Limit int
+	}
+
+	type dto struct {
+		Name    string         `copy:",default=anonymous"`
+		Limit   int            `copy:",default=42"`
+		Timeout *time.Duration `copy:",default=1m"`
+		Role    string
+		Rating  *float64
+	}
+
+	c := New(Tag("copy"))
+	c.Map(&dto{}, &modelV1{}, Default("Role", "guest"), Default("Rating", 4.5))
+
+	dst := dto{}
+	c.Copy(&dst, &modelV1{Limit: 10})
+	equal(t, dst, dto{Name: "anonymous", Limit: 10, Timeout: ptr.Duration(time.Minute), Role: "guest", Rating: ptr.Float64(4.5)})
+
+	other := dto{}
+	c.Copy(&other, &modelV1{Name: "John"})
+	if other.Timeout == dst.Timeout || other.Rating == dst.Rating {
+		t.Error("default pointer values must not be shared")
+	}
+	equal(t, other, dto{Name: "John", Limit: 42, Timeout: ptr.Duration(time.Minute), Role: "guest", Rating: ptr.Float64(4.5)})
+}
+
+func TestCopiers_DefaultInvalid(t *testing.T) {
+	type testStruct1 struct {
+		I int `copy:",default=abc"`
+	}
+
+	type testStruct2 struct {
+		I int
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on invalid default value")
+			}
+		}()
+		New(Tag("copy")).Prepare(&testStruct1{}, &testStruct2{})
+	}()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on not convertible default value")
+			}
+		}()
+		New().Map(&testStruct2{}, &testStruct1{}, Default("I", "abc"))
+	}()
+}
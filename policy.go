@@ -0,0 +1,238 @@
+package copy
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gotidy/copy/structinfo"
+)
+
+// Principal is a caller, whose roles are checked against field policies set by the role tag option.
+type Principal interface {
+	HasRole(role string) bool
+}
+
+// Roles is a principal having the listed roles.
+type Roles []string
+
+// HasRole reports whether the role is in the list.
+func (r Roles) HasRole(role string) bool {
+	for _, s := range r {
+		if s == role {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the context carrying the principal. Fields with the role tag option
+// are copied only if the principal has one of the roles.
+//
+//   type User struct {
+//       Email string `copy:",mask=email"`
+//       Notes string `copy:",role=admin"`
+//   }
+//
+//   c.CopyContext(copy.WithPrincipal(ctx, copy.Roles{"admin"}), &dto, &user)
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal carried by the context.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// MaskFunc masks a string value.
+type MaskFunc func(s string) string
+
+// Mask registers the mask function, that can be referred by the mask tag option.
+// Masks "email", "full" and "last4" are registered by default.
+func Mask(name string, fn MaskFunc) Option {
+	return func(o *Options) {
+		if o.masks == nil {
+			o.masks = make(map[string]MaskFunc)
+		}
+		o.masks[name] = fn
+	}
+}
+
+var defaultMasks = map[string]MaskFunc{
+	"email": MaskEmail,
+	"full":  MaskFull,
+	"last4": MaskLast4,
+}
+
+// MaskFull replaces every character with an asterisk.
+func MaskFull(s string) string {
+	return strings.Repeat("*", utf8.RuneCountInString(s))
+}
+
+// MaskEmail keeps the first character of the local part and the domain of the email address,
+// "john@example.com" becomes "j***@example.com".
+func MaskEmail(s string) string {
+	at := strings.LastIndex(s, "@")
+	if at == -1 {
+		return MaskFull(s)
+	}
+
+	_, size := utf8.DecodeRuneInString(s)
+	if size > at {
+		return s
+	}
+	return s[:size] + MaskFull(s[size:at]) + s[at:]
+}
+
+// MaskLast4 keeps the last four characters, "4111111111111111" becomes "************1111".
+func MaskLast4(s string) string {
+	runes := []rune(s)
+	if len(runes) <= 4 {
+		return s
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+func (c *Copiers) mask(name string) MaskFunc {
+	if fn, ok := c.options.masks[name]; ok {
+		return fn
+	}
+	return defaultMasks[name]
+}
+
+// maskValue returns the function masking the value of the type, string and *string are supported.
+func maskValue(t reflect.Type, mask MaskFunc) func(v reflect.Value) {
+	switch {
+	case t.Kind() == reflect.String:
+		return func(v reflect.Value) {
+			v.SetString(mask(v.String()))
+		}
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.String:
+		return func(v reflect.Value) {
+			if v.IsNil() {
+				return
+			}
+			// The new value is allocated, so a value the pointer points to stays intact.
+			masked := reflect.New(t.Elem())
+			masked.Elem().SetString(mask(v.Elem().String()))
+			v.Set(masked)
+		}
+	}
+	return nil
+}
+
+// fieldMask returns the function masking values of the type by the mask of the field,
+// if the field has no mask then nil is returned. Name is the name of the masked field.
+func (c *Copiers) fieldMask(f structinfo.Field, name string, t reflect.Type) func(v reflect.Value) {
+	if f.Policy.Mask == "" {
+		return nil
+	}
+
+	mask := c.mask(f.Policy.Mask)
+	if mask == nil {
+		panic(fmt.Errorf("mask «%s» of field «%s» is not registered", f.Policy.Mask, f.Name))
+	}
+	m := maskValue(t, mask)
+	if m == nil {
+		panic(fmt.Errorf("field «%s» of type «%s» can not be masked", name, t))
+	}
+	return m
+}
+
+// readValue returns the value of the source field the way copying reads it and false if the field is not copied:
+// the field is in a nil embedded struct, the principal has none of its roles or it is empty and has omitempty.
+// Masks of the field and of the destination fields are applied to a copy of the value.
+func (c *Copiers) readValue(ctx context.Context, v reflect.Value, f structinfo.Field, dst ...structinfo.Field) (reflect.Value, bool) {
+	fields := append([]structinfo.Field{f}, dst...)
+
+	omitEmpty := false
+	for _, field := range fields {
+		if len(field.Policy.Roles) > 0 && !allowed(ctx, field.Policy.Roles) {
+			return reflect.Value{}, false
+		}
+		omitEmpty = omitEmpty || field.Options.OmitEmpty
+	}
+
+	fv, ok := fieldValue(v, f)
+	if !ok || omitEmpty && fv.IsZero() {
+		return reflect.Value{}, false
+	}
+
+	masks := []func(v reflect.Value){c.fieldMask(f, f.Name, f.Type)}
+	for _, d := range dst {
+		// Masks of destination fields of other types are checked when copiers are prepared.
+		if mask := c.mask(d.Policy.Mask); mask != nil {
+			masks = append(masks, maskValue(f.Type, mask))
+		}
+	}
+	for _, mask := range masks {
+		if mask != nil {
+			masked := reflect.New(f.Type).Elem()
+			masked.Set(fv)
+			mask(masked)
+			fv = masked
+		}
+	}
+
+	return fv, true
+}
+
+// policyCopier applies policies of the fields to the copier.
+func (c *Copiers) policyCopier(copier fieldCopier, dst, src structinfo.Field) fieldCopier {
+	var roles [][]string
+	var masks []func(v reflect.Value)
+	for _, f := range []structinfo.Field{src, dst} {
+		if len(f.Policy.Roles) > 0 {
+			roles = append(roles, f.Policy.Roles)
+		}
+
+		if m := c.fieldMask(f, dst.Name, dst.Type); m != nil {
+			masks = append(masks, m)
+		}
+	}
+
+	if len(roles) == 0 && len(masks) == 0 {
+		return copier
+	}
+
+	return func(ctx context.Context, dstPtr, srcPtr pointer) error {
+		for _, roles := range roles {
+			if !allowed(ctx, roles) {
+				return nil
+			}
+		}
+
+		if err := copier(ctx, dstPtr, srcPtr); err != nil {
+			return err
+		}
+
+		if p, ok := fieldPtr(dstPtr, dst); ok && len(masks) > 0 {
+			v := valueAt(p, dst.Type)
+			for _, mask := range masks {
+				mask(v)
+			}
+		}
+
+		return nil
+	}
+}
+
+// allowed reports whether the principal carried by the context has one of the roles.
+func allowed(ctx context.Context, roles []string) bool {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, role := range roles {
+		if p.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
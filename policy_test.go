@@ -0,0 +1,140 @@
+package copy
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/gotidy/ptr"
+)
+
+func TestCopiers_Policy(t *testing.T) {
+	type model struct {
+		Email  string  `copy:",mask=email"`
+		Phone  *string `copy:",mask=last4"`
+		Notes  string  `copy:",role=admin"`
+		Secret string  `copy:"Code,role=admin|support,mask=full"`
+	}
+
+	type dto struct {
+		Email string
+		Phone *string
+		Notes string
+		Code  string
+	}
+
+	phone := "+12025550123"
+	src := model{Email: "john.smith@joy.me", Phone: &phone, Notes: "notes", Secret: "secret"}
+	c := New(Tag("copy"))
+
+	dst := dto{}
+	if err := c.CopyContext(context.Background(), &dst, &src); err != nil {
+		t.Fatalf("copy: %s", err)
+	}
+	equal(t, dst, dto{Email: "j*********@joy.me", Phone: ptr.String("********0123")})
+	if phone != "+12025550123" {
+		t.Errorf("source must stay intact, got «%s»", phone)
+	}
+
+	dst = dto{}
+	if err := c.CopyContext(WithPrincipal(context.Background(), Roles{"support"}), &dst, &src); err != nil {
+		t.Fatalf("copy: %s", err)
+	}
+	equal(t, dst, dto{Email: "j*********@joy.me", Phone: ptr.String("********0123"), Code: "******"})
+
+	dst = dto{}
+	if err := c.CopyContext(WithPrincipal(context.Background(), Roles{"admin"}), &dst, &src); err != nil {
+		t.Fatalf("copy: %s", err)
+	}
+	equal(t, dst, dto{Email: "j*********@joy.me", Phone: ptr.String("********0123"), Notes: "notes", Code: "******"})
+}
+
+func TestCopiers_PolicyReading(t *testing.T) {
+	type model struct {
+		Email string `copy:",mask=email"`
+		Notes string `copy:",role=admin"`
+		Nick  string `copy:",omitempty"`
+	}
+
+	src := model{Email: "john.smith@joy.me", Notes: "notes"}
+	c := New(Tag("copy"))
+
+	m := c.ToMap(&src)
+	want := map[string]interface{}{"Email": "j*********@joy.me"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("want «%v» got «%v»", want, m)
+	}
+
+	m = c.ToMapContext(WithPrincipal(context.Background(), Roles{"admin"}), &src)
+	want = map[string]interface{}{"Email": "j*********@joy.me", "Notes": "notes"}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("want «%v» got «%v»", want, m)
+	}
+
+	values, err := c.ToValues(&src)
+	if err != nil {
+		t.Fatalf("to values: %s", err)
+	}
+	if want := (url.Values{"Email": {"j*********@joy.me"}}); !reflect.DeepEqual(values, want) {
+		t.Errorf("want «%v» got «%v»", want, values)
+	}
+
+	type dto struct {
+		Email string
+		Notes string
+		Nick  string
+	}
+
+	if changes := c.Diff(&src, &dto{Email: "j*********@joy.me", Nick: "nick"}); len(changes) != 0 {
+		t.Errorf("want no changes got «%+v»", changes)
+	}
+	changes := c.Diff(&src, &dto{})
+	if len(changes) != 1 || changes[0].Old != "j*********@joy.me" {
+		t.Errorf("want the masked email change got «%+v»", changes)
+	}
+}
+
+func TestCopiers_Mask(t *testing.T) {
+	type testStruct1 struct {
+		S string `copy:",mask=upper"`
+	}
+
+	type testStruct2 struct {
+		S string
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("must panic on unregistered mask")
+			}
+		}()
+		New(Tag("copy")).Prepare(&testStruct2{}, &testStruct1{})
+	}()
+
+	dst := testStruct2{}
+	New(Tag("copy"), Mask("upper", func(s string) string { return "UPPER" })).Copy(&dst, &testStruct1{S: "s"})
+	if dst.S != "UPPER" {
+		t.Errorf("want «UPPER» got «%s»", dst.S)
+	}
+}
+
+func TestMasks(t *testing.T) {
+	for _, test := range []struct {
+		mask     MaskFunc
+		value    string
+		expected string
+	}{
+		{MaskEmail, "john@example.com", "j***@example.com"},
+		{MaskEmail, "j@example.com", "j@example.com"},
+		{MaskEmail, "john", "****"},
+		{MaskFull, "пароль", "******"},
+		{MaskLast4, "4111111111111111", "************1111"},
+		{MaskLast4, "111", "111"},
+	} {
+		if actual := test.mask(test.value); actual != test.expected {
+			t.Errorf("want «%s» got «%s»", test.expected, actual)
+		}
+	}
+}
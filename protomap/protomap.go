@@ -0,0 +1,120 @@
+// Package protomap provides copying between domain structs and generated protobuf messages.
+//
+// Copiers returned by New understand conventions of generated code. Internal fields, such as state, sizeCache
+// and XXX_ fields, are skipped. Wrappers, such as *wrapperspb.StringValue, are converted to the wrapped values
+// and pointers to them. *timestamppb.Timestamp and *durationpb.Duration are converted to time.Time and time.Duration.
+// Oneof fields are copied to and from the struct fields named as the fields of the oneof.
+//
+//   c := protomap.New()
+//   c.Copy(&msg, &user)
+package protomap
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/gotidy/copy"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var messageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// New creates Copiers for copying between domain structs and generated protobuf messages.
+// Options are applied after the protobuf ones, so registered converters take precedence.
+func New(options ...copy.Option) *copy.Copiers {
+	var c *copy.Copiers
+
+	opts := []copy.Option{
+		copy.Ignore(func(name string) bool { return strings.HasPrefix(name, "XXX_") }),
+		copy.TypeHook(func(dst, src reflect.Type) copy.HookFunc { return oneofHook(c, dst, src) }),
+	}
+	for _, w := range wrappers {
+		opts = append(opts, wrapperConverters(w)...)
+	}
+	opts = append(opts, timeConverters()...)
+	opts = append(opts, durationConverters()...)
+
+	c = copy.New(append(opts, options...)...)
+	return c
+}
+
+// oneof is a oneof field of a message struct.
+type oneof struct {
+	index    int            // Index of the interface field.
+	wrappers []reflect.Type // Pointers to the structs wrapping the fields of the oneof.
+}
+
+// oneofs returns oneof fields of the struct, if it is not a message then nil is returned.
+func oneofs(t reflect.Type) []oneof {
+	if !reflect.PtrTo(t).Implements(messageType) {
+		return nil
+	}
+
+	var result []oneof
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := t.Field(i).Tag.Lookup("protobuf_oneof")
+		if !ok {
+			continue
+		}
+
+		// Wrappers are not exported by the generated code, so they are taken from the field set through reflection.
+		v := reflect.New(t)
+		m := v.Interface().(proto.Message).ProtoReflect()
+		fields := m.Descriptor().Oneofs().ByName(protoreflect.Name(name)).Fields()
+
+		o := oneof{index: i}
+		for j := 0; j < fields.Len(); j++ {
+			m.Set(fields.Get(j), m.NewField(fields.Get(j)))
+			o.wrappers = append(o.wrappers, v.Elem().Field(i).Elem().Type())
+		}
+		result = append(result, o)
+	}
+
+	return result
+}
+
+// oneofHook returns the hook copying oneof fields of the message to the fields of the struct or back.
+// From the struct the first field with a non-zero value is set to the oneof.
+func oneofHook(c *copy.Copiers, dst, src reflect.Type) copy.HookFunc {
+	srcOneofs := oneofs(src)
+	dstOneofs := oneofs(dst)
+
+	switch {
+	case len(srcOneofs) > 0 && !reflect.PtrTo(dst).Implements(messageType):
+		return func(ctx context.Context, dst, src reflect.Value) error {
+			for _, o := range srcOneofs {
+				w := src.Elem().Field(o.index)
+				if w.IsNil() || w.Elem().IsNil() {
+					continue
+				}
+				if err := c.CopyContext(ctx, dst.Interface(), w.Elem().Interface()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case len(dstOneofs) > 0 && !reflect.PtrTo(src).Implements(messageType):
+		return func(ctx context.Context, dst, src reflect.Value) error {
+			for _, o := range dstOneofs {
+				field := dst.Elem().Field(o.index)
+				field.Set(reflect.Zero(field.Type()))
+
+				for _, t := range o.wrappers {
+					w := reflect.New(t.Elem())
+					if err := c.CopyContext(ctx, w.Interface(), src.Interface()); err != nil {
+						return err
+					}
+					if !w.Elem().IsZero() {
+						field.Set(w)
+						break
+					}
+				}
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
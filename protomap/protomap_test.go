@@ -0,0 +1,113 @@
+package protomap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testMessage struct {
+	Name             *wrapperspb.StringValue
+	Age              *wrapperspb.Int32Value
+	Nick             *wrapperspb.StringValue
+	CreatedAt        *timestamppb.Timestamp
+	DeletedAt        *timestamppb.Timestamp
+	TTL              *durationpb.Duration
+	Value            *structpb.Value
+	XXX_unrecognized []byte
+}
+
+type testValue struct {
+	NumberValue float64
+	StringValue string
+	BoolValue   bool
+}
+
+type testDomain struct {
+	Name             string
+	Age              *int32
+	Nick             *string
+	CreatedAt        time.Time
+	DeletedAt        *time.Time
+	TTL              time.Duration
+	Value            testValue
+	XXX_unrecognized []byte
+}
+
+func TestNew(t *testing.T) {
+	c := New()
+
+	created := time.Date(2021, 2, 18, 16, 0, 1, 0, time.UTC)
+	domain := testDomain{
+		Name:             "John",
+		Age:              ptr.Int32(33),
+		CreatedAt:        created,
+		TTL:              time.Minute,
+		Value:            testValue{StringValue: "text"},
+		XXX_unrecognized: []byte{1},
+	}
+
+	msg := testMessage{}
+	c.Copy(&msg, &domain)
+
+	expected := testMessage{
+		Name:      wrapperspb.String("John"),
+		Age:       wrapperspb.Int32(33),
+		CreatedAt: timestamppb.New(created),
+		TTL:       durationpb.New(time.Minute),
+		Value:     structpb.NewStringValue("text"),
+	}
+	if msg.Nick != nil || msg.DeletedAt != nil || msg.XXX_unrecognized != nil {
+		t.Errorf("nil fields must stay nil, got «%v», «%v», «%v»", msg.Nick, msg.DeletedAt, msg.XXX_unrecognized)
+	}
+	for _, pair := range [][2]proto.Message{
+		{expected.Name, msg.Name},
+		{expected.Age, msg.Age},
+		{expected.CreatedAt, msg.CreatedAt},
+		{expected.TTL, msg.TTL},
+		{expected.Value, msg.Value},
+	} {
+		if !proto.Equal(pair[0], pair[1]) {
+			t.Errorf("want «%v» got «%v»", pair[0], pair[1])
+		}
+	}
+
+	back := testDomain{}
+	c.Copy(&back, &msg)
+	domain.XXX_unrecognized = nil
+	if back.Name != domain.Name || *back.Age != *domain.Age || back.Nick != nil || !back.CreatedAt.Equal(created) ||
+		back.DeletedAt != nil || back.TTL != domain.TTL || back.Value != domain.Value || back.XXX_unrecognized != nil {
+		t.Errorf("want «%+v» got «%+v»", domain, back)
+	}
+}
+
+func TestNew_Oneof(t *testing.T) {
+	c := New()
+
+	for _, value := range []testValue{
+		{NumberValue: 1.5},
+		{StringValue: "text"},
+		{BoolValue: true},
+	} {
+		msg := structpb.Value{}
+		c.Copy(&msg, &value)
+
+		back := testValue{}
+		c.Copy(&back, &msg)
+		if back != value {
+			t.Errorf("want «%+v» got «%+v»", value, back)
+		}
+	}
+
+	msg := structpb.NewBoolValue(true)
+	c.Copy(msg, &testValue{})
+	if msg.Kind != nil {
+		t.Errorf("oneof of zero fields must be nil, got «%v»", msg.Kind)
+	}
+}
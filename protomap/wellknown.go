@@ -0,0 +1,160 @@
+package protomap
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/gotidy/copy"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var wrappers = []interface{}{
+	(*wrapperspb.DoubleValue)(nil),
+	(*wrapperspb.FloatValue)(nil),
+	(*wrapperspb.Int64Value)(nil),
+	(*wrapperspb.UInt64Value)(nil),
+	(*wrapperspb.Int32Value)(nil),
+	(*wrapperspb.UInt32Value)(nil),
+	(*wrapperspb.BoolValue)(nil),
+	(*wrapperspb.StringValue)(nil),
+	(*wrapperspb.BytesValue)(nil),
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// converterOf returns the converter option of the function converting src into dst.
+func converterOf(dst, src reflect.Type, fn func(dst, src reflect.Value)) copy.Option {
+	t := reflect.FuncOf([]reflect.Type{reflect.PtrTo(dst), src}, []reflect.Type{errorType}, false)
+	f := reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		fn(args[0].Elem(), args[1])
+		return []reflect.Value{reflect.Zero(errorType)}
+	})
+
+	return copy.Converter(f.Interface())
+}
+
+// wrapperConverters returns converters of the wrapper, such as *wrapperspb.StringValue,
+// to the wrapped value and the pointer to it and back. Nil wrappers are converted to nil pointers and zero values.
+func wrapperConverters(wrapper interface{}) []copy.Option {
+	w := reflect.TypeOf(wrapper)
+	i := wrapperValueIndex(w)
+	t := w.Elem().Field(i).Type
+	p := reflect.PtrTo(t)
+
+	return []copy.Option{
+		// *W -> T
+		converterOf(t, w, func(dst, src reflect.Value) {
+			if src.IsNil() {
+				dst.Set(reflect.Zero(t))
+				return
+			}
+			dst.Set(src.Elem().Field(i))
+		}),
+		// *W -> *T
+		converterOf(p, w, func(dst, src reflect.Value) {
+			if src.IsNil() {
+				dst.Set(reflect.Zero(p))
+				return
+			}
+			v := reflect.New(t)
+			v.Elem().Set(src.Elem().Field(i))
+			dst.Set(v)
+		}),
+		// T -> *W
+		converterOf(w, t, func(dst, src reflect.Value) {
+			v := reflect.New(w.Elem())
+			v.Elem().Field(i).Set(src)
+			dst.Set(v)
+		}),
+		// *T -> *W
+		converterOf(w, p, func(dst, src reflect.Value) {
+			if src.IsNil() {
+				dst.Set(reflect.Zero(w))
+				return
+			}
+			v := reflect.New(w.Elem())
+			v.Elem().Field(i).Set(src.Elem())
+			dst.Set(v)
+		}),
+	}
+}
+
+// wrapperValueIndex returns the index of the Value field of the wrapper struct.
+func wrapperValueIndex(w reflect.Type) int {
+	f, ok := w.Elem().FieldByName("Value")
+	if !ok {
+		panic("wrapper «" + w.String() + "» has no field «Value»")
+	}
+	return f.Index[0]
+}
+
+// timeConverters returns converters of *timestamppb.Timestamp to time.Time and *time.Time and back.
+// Nil timestamps are converted to zero times and nil pointers, zero times are converted to nil timestamps.
+func timeConverters() []copy.Option {
+	return []copy.Option{
+		copy.Converter(func(dst *time.Time, src *timestamppb.Timestamp) error {
+			*dst = time.Time{}
+			if src != nil {
+				*dst = src.AsTime()
+			}
+			return nil
+		}),
+		copy.Converter(func(dst **time.Time, src *timestamppb.Timestamp) error {
+			*dst = nil
+			if src != nil {
+				t := src.AsTime()
+				*dst = &t
+			}
+			return nil
+		}),
+		copy.Converter(func(dst **timestamppb.Timestamp, src time.Time) error {
+			*dst = nil
+			if !src.IsZero() {
+				*dst = timestamppb.New(src)
+			}
+			return nil
+		}),
+		copy.Converter(func(dst **timestamppb.Timestamp, src *time.Time) error {
+			*dst = nil
+			if src != nil {
+				*dst = timestamppb.New(*src)
+			}
+			return nil
+		}),
+	}
+}
+
+// durationConverters returns converters of *durationpb.Duration to time.Duration and *time.Duration and back.
+// Nil durations are converted to zero durations and nil pointers.
+func durationConverters() []copy.Option {
+	return []copy.Option{
+		copy.Converter(func(dst *time.Duration, src *durationpb.Duration) error {
+			*dst = 0
+			if src != nil {
+				*dst = src.AsDuration()
+			}
+			return nil
+		}),
+		copy.Converter(func(dst **time.Duration, src *durationpb.Duration) error {
+			*dst = nil
+			if src != nil {
+				d := src.AsDuration()
+				*dst = &d
+			}
+			return nil
+		}),
+		copy.Converter(func(dst **durationpb.Duration, src time.Duration) error {
+			*dst = durationpb.New(src)
+			return nil
+		}),
+		copy.Converter(func(dst **durationpb.Duration, src *time.Duration) error {
+			*dst = nil
+			if src != nil {
+				*dst = durationpb.New(*src)
+			}
+			return nil
+		}),
+	}
+}
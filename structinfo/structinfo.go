@@ -0,0 +1,278 @@
+// Package structinfo provides struct metadata the way the copy package sees it: names of fields resolved
+// by tags and embedding rules, tag options, offsets, index sequences and embedded pointers on the way to fields.
+// It allows other tools to agree with copiers about fields of types.
+//
+//   s := structinfo.Shared("copy").Get(&User{})
+//   for _, f := range s.Fields {
+//       fmt.Println(f.Name, f.Index, f.Options.ReadOnly)
+//   }
+package structinfo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gotidy/copy/internal/lru"
+)
+
+// Field info.
+type Field struct {
+	Type       reflect.Type
+	Name       string
+	Anonymous  bool
+	Offset     uintptr // Offset from the struct the last step of the path points to.
+	Path       []Step  // Embedded pointers to structs on the way to the field.
+	Index      []int   // Index sequence for reflect.Value.FieldByIndex.
+	Depth      int     // Depth of embedding, fields of the struct itself have the zero depth.
+	ParentName string
+	Policy     Policy
+	Default    string     // Default value set by the default tag option.
+	Options    TagOptions // Options set by the tag.
+}
+
+// Step is an embedded pointer to struct on the way to a field.
+type Step struct {
+	Offset uintptr      // Offset of the pointer from the struct the previous step points to.
+	Type   reflect.Type // Type of the struct the pointer points to.
+}
+
+// embeddable reports whether fields of the embedded type are promoted.
+func embeddable(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
+}
+
+// Struct fields info.
+type Struct struct {
+	Fields    []Field            // Visible fields.
+	Names     map[string]Field   // Visible fields by names.
+	Ambiguous map[string][]Field // Fields with the same name at the same depth, they hide each other.
+}
+
+// NewStruct inits the new struct info. Names of the fields are taken from tags with the names in priority order,
+// the first tag is parsed with all options, the others provide only names by conventions of their families,
+// such as json. Malformed first tags cause an error.
+// Names are resolved like selectors of Go: the shallowest field hides deeper fields with the same name,
+// and fields with the same name at the same depth are ambiguous, they and deeper fields are not visible.
+func NewStruct(t reflect.Type, tagNames ...string) (Struct, error) {
+	fields := make([]Field, 0, t.NumField())
+
+	// Structs on the way to the traversed struct, embedded pointers to them are not traversed to avoid cycles.
+	visiting := map[reflect.Type]bool{t: true}
+
+	// Fields of embedded structs inherit directional options and prefixes of the struct field.
+	var traverse func(t reflect.Type, parent Field) error
+	traverse = func(t reflect.Type, parent Field) error {
+		offset, path := parent.Offset, parent.Path
+		if parent.Type != nil && parent.Type.Kind() == reflect.Ptr {
+			offset, path = 0, append(append(make([]Step, 0, len(path)+1), path...), Step{Offset: parent.Offset, Type: t})
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+
+			fi := Field{
+				Type:       field.Type,
+				Name:       field.Name,
+				Offset:     field.Offset + offset,
+				Path:       path,
+				Index:      append(append(make([]int, 0, len(parent.Index)+1), parent.Index...), i),
+				Depth:      len(parent.Index),
+				Anonymous:  field.Anonymous && embeddable(field.Type),
+				ParentName: parent.Name,
+			}
+
+			kind, err := fieldTag(field, tagNames, &fi)
+			if err != nil {
+				return fmt.Errorf("field «%s»: %w", field.Name, err)
+			}
+			fi.Options.ReadOnly = fi.Options.ReadOnly || parent.Options.ReadOnly
+			fi.Options.WriteOnly = fi.Options.WriteOnly || parent.Options.WriteOnly
+			fi.Name = parent.Options.Prefix + fi.Name
+
+			switch kind {
+			case tagOmit:
+				continue
+			case tagEmbed:
+				fi.Anonymous = embeddable(field.Type)
+			}
+			elem := fi.Type
+			if fi.Anonymous && elem.Kind() == reflect.Ptr {
+				elem = elem.Elem()
+				fi.Anonymous = !visiting[elem]
+			}
+			if fi.Anonymous {
+				fi.Options.Prefix = parent.Options.Prefix + fi.Options.Prefix
+			}
+
+			fields = append(fields, fi)
+
+			if fi.Anonymous {
+				visiting[elem] = true
+				err := traverse(elem, fi)
+				delete(visiting, elem)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := traverse(t, Field{}); err != nil {
+		return Struct{}, err
+	}
+
+	return resolve(fields), nil
+}
+
+// resolve returns the struct with the visible fields.
+func resolve(fields []Field) Struct {
+	depths := make(map[string]int, len(fields))
+	counts := make(map[string]int, len(fields))
+	for _, f := range fields {
+		depth, ok := depths[f.Name]
+		switch {
+		case !ok || f.Depth < depth:
+			depths[f.Name] = f.Depth
+			counts[f.Name] = 1
+		case f.Depth == depth:
+			counts[f.Name]++
+		}
+	}
+
+	s := Struct{Fields: make([]Field, 0, len(fields)), Names: make(map[string]Field, len(fields))}
+	for _, f := range fields {
+		if f.Depth != depths[f.Name] {
+			continue
+		}
+		if counts[f.Name] > 1 {
+			if s.Ambiguous == nil {
+				s.Ambiguous = make(map[string][]Field)
+			}
+			s.Ambiguous[f.Name] = append(s.Ambiguous[f.Name], f)
+			continue
+		}
+		s.Fields = append(s.Fields, f)
+		s.Names[f.Name] = f
+	}
+
+	return s
+}
+
+// Field returns a struct type's i'th field.
+// It panics if i is not in the range [0, NumField()).
+func (s Struct) Field(i int) Field {
+	return s.Fields[i]
+}
+
+// FieldByName returns the struct field with the given name
+// and a boolean indicating if the field was found.
+func (s Struct) FieldByName(name string) (Field, bool) {
+	f, ok := s.Names[name]
+	return f, ok
+}
+
+// NumField returns a struct type's field count.
+// It panics if the type's Kind is not Struct.
+func (s Struct) NumField() int {
+	return len(s.Fields)
+}
+
+// Cache is structs' cache.
+type Cache struct {
+	tags    []string
+	structs *lru.Cache
+}
+
+// New creates structs Cache. Tag names are consulted in priority order.
+func New(tagNames ...string) *Cache {
+	return NewLRU(0, tagNames...)
+}
+
+// NewLRU creates structs Cache, that keeps at most maxEntries structs evicting the least recently used ones.
+// The cache is not limited if maxEntries is not positive.
+func NewLRU(maxEntries int, tagNames ...string) *Cache {
+	return &Cache{tags: tagNames, structs: lru.New(maxEntries)}
+}
+
+// CacheStats is statistics of a cache.
+type CacheStats struct {
+	Hits      uint64 // Number of lookups of cached entries.
+	Misses    uint64 // Number of lookups of missing entries.
+	Evictions uint64 // Number of evicted entries.
+	Entries   int    // Number of cached entries.
+}
+
+// Stats returns statistics of the cache.
+func (c *Cache) Stats() CacheStats {
+	return CacheStats(c.structs.Stats())
+}
+
+// Forget removes the struct info of the type, it is created again on demand.
+func (c *Cache) Forget(t reflect.Type) {
+	c.structs.Remove(t)
+}
+
+// Reset removes all struct infos and resets statistics.
+func (c *Cache) Reset() {
+	c.structs.Reset()
+}
+
+// Tags returns the tag names consulted in priority order.
+func (c *Cache) Tags() []string {
+	return append([]string(nil), c.tags...)
+}
+
+var shared = struct {
+	mu     sync.Mutex
+	caches map[string]*Cache
+}{caches: make(map[string]*Cache)}
+
+// Shared returns the cache shared by all users of the tag names, such as copiers created with the same tags.
+func Shared(tagNames ...string) *Cache {
+	key := strings.Join(tagNames, "\x00")
+
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+
+	c, ok := shared.caches[key]
+	if !ok {
+		c = New(tagNames...)
+		shared.caches[key] = c
+	}
+	return c
+}
+
+// Get returns struct fields info.
+func (c *Cache) Get(i interface{}) Struct {
+	t := reflect.TypeOf(i)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return c.GetByType(t)
+}
+
+// GetByType returns struct fields info. It panics if the struct has malformed tags.
+func (c *Cache) GetByType(t reflect.Type) Struct {
+	if s, ok := c.structs.Lookup(t); ok {
+		return s.(Struct)
+	}
+
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Errorf("type %s is not struct", t))
+	}
+
+	// Concurrent first requests of the type wait for the one inspecting the struct, other types are inspected in parallel.
+	return c.structs.Do(t, func() interface{} {
+		s, err := NewStruct(t, c.tags...)
+		if err != nil {
+			panic(fmt.Errorf("struct «%s»: %w", t, err))
+		}
+		return s
+	}).(Struct)
+}
@@ -0,0 +1,169 @@
+package structinfo
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type Base struct {
+	ID   int
+	Name string
+}
+
+type testStruct struct {
+	*Base
+	Name     string  `copy:"name,readonly"`
+	Skipped  string  `copy:"-"`
+	Billing  Address `copy:"+,prefix"`
+	Email    string  `json:"email"`
+	internal string
+}
+
+type Address struct {
+	City string
+}
+
+func TestNewStruct(t *testing.T) {
+	s, err := NewStruct(reflect.TypeOf(testStruct{}), "copy", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, f := range s.Fields {
+		names = append(names, f.Name)
+	}
+	expected := []string{"Base", "ID", "Name", "name", "Billing", "BillingCity", "email"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("want «%v» got «%v»", expected, names)
+	}
+
+	id, ok := s.FieldByName("ID")
+	if !ok || len(id.Path) != 1 || !reflect.DeepEqual(id.Index, []int{0, 0}) || id.Depth != 1 {
+		t.Errorf("unexpected field «%+v»", id)
+	}
+	if f, _ := s.FieldByName("name"); !f.Options.ReadOnly {
+		t.Errorf("field «name» must be read-only")
+	}
+	if f, _ := s.FieldByName("BillingCity"); f.ParentName != "Billing" {
+		t.Errorf("want parent «Billing» got «%s»", f.ParentName)
+	}
+
+	if _, err := NewStruct(reflect.TypeOf(struct {
+		S string `copy:",unknown"`
+	}{}), "copy"); err == nil {
+		t.Error("must fail on unknown tag option")
+	}
+
+	// Fallback tags are parsed by conventions of their families.
+	s, err = NewStruct(reflect.TypeOf(struct {
+		ID   int    `json:"id,string"`
+		Dash string `json:"-,"`
+		Skip string `json:"-"`
+		Key  string `json:",omitempty" db:"key"`
+	}{}), "copy", "json", "db")
+	if err != nil {
+		t.Fatalf("tags of other families must not be checked: %s", err)
+	}
+	names = names[:0]
+	for _, f := range s.Fields {
+		names = append(names, f.Name)
+	}
+	if expected := []string{"id", "-", "key"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("want «%v» got «%v»", expected, names)
+	}
+
+	// The first tag is parsed with all options, whatever its name.
+	type Inner struct{ Bee int }
+	s, err = NewStruct(reflect.TypeOf(struct {
+		In   Inner  `dto:"+"`
+		Name string `dto:"name,readonly" json:"title"`
+	}{}), "dto", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.FieldByName("Bee"); !ok {
+		t.Error("struct with the custom tag «+» must be inlined")
+	}
+	if f, ok := s.FieldByName("name"); !ok || !f.Options.ReadOnly {
+		t.Errorf("field «name» must be read-only by the custom tag, got «%+v»", f)
+	}
+	if _, err := NewStruct(reflect.TypeOf(struct {
+		S string `dto:",unknown"`
+	}{}), "dto"); err == nil {
+		t.Error("must fail on unknown option of the custom tag")
+	}
+}
+
+func TestShared(t *testing.T) {
+	if Shared("copy", "json") != Shared("copy", "json") {
+		t.Error("caches of the same tags must be shared")
+	}
+	if Shared("copy") == Shared("copy", "json") {
+		t.Error("caches of different tags must not be shared")
+	}
+
+	c := Shared("copy")
+	if !reflect.DeepEqual(c.Get(&testStruct{}), c.GetByType(reflect.TypeOf(testStruct{}))) {
+		t.Error("Get and GetByType must return the same struct")
+	}
+}
+
+func TestNewLRU(t *testing.T) {
+	type A struct{ V int }
+	type B struct{ V int }
+
+	c := NewLRU(1)
+	c.Get(&A{})
+	c.Get(&A{})
+	c.Get(&B{})
+	want := CacheStats{Hits: 1, Misses: 2, Evictions: 1, Entries: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("want «%+v» got «%+v»", want, got)
+	}
+
+	c.Forget(reflect.TypeOf(B{}))
+	if got := c.Stats().Entries; got != 0 {
+		t.Errorf("want «0» entries got «%d»", got)
+	}
+
+	c.Get(&A{})
+	c.Reset()
+	if got := c.Stats(); got != (CacheStats{}) {
+		t.Errorf("want empty stats got «%+v»", got)
+	}
+}
+
+func BenchmarkGetByTypeParallel(b *testing.B) {
+	c := New("copy")
+	typ := reflect.TypeOf(testStruct{})
+	c.GetByType(typ)
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			c.GetByType(typ)
+		}
+	})
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := New("copy")
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			c.Get(&testStruct{})
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	want := CacheStats{Hits: 31, Misses: 1, Entries: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("want «%+v» got «%+v»", want, got)
+	}
+}
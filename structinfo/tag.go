@@ -0,0 +1,203 @@
+package structinfo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Policy is the access policy of a field, set by mask and role tag options.
+type Policy struct {
+	Mask  string   // Name of the mask applied to the field value.
+	Roles []string // One of the roles is required to copy the field.
+}
+
+// TagOptions are options of a field set by the tag.
+type TagOptions struct {
+	OmitEmpty bool              // Zero source values are not copied.
+	Required  bool              // The destination field must be present in the source.
+	ReadOnly  bool              // The field is not filled as a destination.
+	WriteOnly bool              // The field is not read as a source.
+	Inline    bool              // Fields of the struct are copied as fields of the containing struct.
+	Prefix    string            // Prefix of names of the inlined fields, including prefixes of containing structs.
+	Conv      string            // Name of the converter of the field.
+	Extra     map[string]string // Options with the "x-" prefix, that are reserved for extensions.
+}
+
+type tagKind int
+
+const (
+	tagNormal tagKind = iota
+	tagOmit
+	tagEmbed
+)
+
+// parseTag parses the tag of the form "name,option,key=value" into the field.
+// The name is a field name, "-" omits the field, "+" inlines the struct. Options are:
+//
+//   omitempty     zero source values are not copied
+//   required      the destination field must be present in the source
+//   readonly      the field is not filled as a destination
+//   writeonly     the field is not read as a source
+//   inline        the same as "+"
+//   prefix=p      prefix of names of the inlined fields
+//   prefix        the field name is the prefix of names of the inlined fields
+//   default=v     default value of the destination field
+//   conv=name     the named converter copies the field
+//   mask=name     the named mask is applied to the copied value
+//   role=a|b      one of the roles is required to copy the field
+//   x-key[=value] options reserved for extensions
+func parseTag(tag string, f *Field) (tagKind, error) {
+	options := strings.Split(tag, ",")
+
+	kind := tagNormal
+	switch options[0] {
+	case "-":
+		return tagOmit, nil
+	case "+":
+		kind = tagEmbed
+	default:
+		if options[0] != "" {
+			f.Name = options[0]
+		}
+	}
+
+	seen := make(map[string]bool, len(options)-1)
+	for _, option := range options[1:] {
+		key, value, hasValue := option, "", false
+		if idx := strings.Index(option, "="); idx != -1 {
+			key, value, hasValue = option[:idx], option[idx+1:], true
+		}
+
+		if seen[key] {
+			return kind, fmt.Errorf("duplicate tag option «%s»", key)
+		}
+		seen[key] = true
+
+		if strings.HasPrefix(key, "x-") {
+			if f.Options.Extra == nil {
+				f.Options.Extra = make(map[string]string)
+			}
+			f.Options.Extra[key] = value
+			continue
+		}
+
+		var flag *bool
+		switch key {
+		case "omitempty":
+			flag = &f.Options.OmitEmpty
+		case "required":
+			flag = &f.Options.Required
+		case "readonly":
+			flag = &f.Options.ReadOnly
+		case "writeonly":
+			flag = &f.Options.WriteOnly
+		case "inline":
+			flag = &f.Options.Inline
+		case "prefix":
+			if hasValue && value == "" {
+				return kind, fmt.Errorf("tag option «%s» requires a value", key)
+			}
+		case "default", "conv", "mask", "role":
+			if value == "" {
+				return kind, fmt.Errorf("tag option «%s» requires a value", key)
+			}
+		case "":
+			return kind, fmt.Errorf("empty tag option")
+		default:
+			return kind, fmt.Errorf("unknown tag option «%s»", key)
+		}
+
+		if flag != nil {
+			if hasValue {
+				return kind, fmt.Errorf("tag option «%s» does not take a value", key)
+			}
+			*flag = true
+			continue
+		}
+
+		switch key {
+		case "prefix":
+			f.Options.Prefix = value
+			if !hasValue {
+				f.Options.Prefix = f.Name
+			}
+		case "default":
+			f.Default = value
+		case "conv":
+			f.Options.Conv = value
+		case "mask":
+			f.Policy.Mask = value
+		case "role":
+			f.Policy.Roles = append(f.Policy.Roles, strings.Split(value, "|")...)
+		}
+	}
+
+	if f.Options.ReadOnly && f.Options.WriteOnly {
+		return kind, fmt.Errorf("tag options «readonly» and «writeonly» are mutually exclusive")
+	}
+	if f.Options.Inline {
+		kind = tagEmbed
+	}
+	if seen["prefix"] && kind != tagEmbed && !f.Anonymous {
+		return kind, fmt.Errorf("tag option «prefix» requires inlining")
+	}
+
+	return kind, nil
+}
+
+// parseFallbackTag parses a tag of other families, such as json or db. Only the name is taken,
+// "-" omits the field, "-," names the field "-".
+func parseFallbackTag(tag string) (name string, omit bool) {
+	if tag == "-" {
+		return "", true
+	}
+	if idx := strings.Index(tag, ","); idx != -1 {
+		return tag[:idx], false
+	}
+	return tag, false
+}
+
+// fieldTag parses tags of the field into f. The first tag name is the own tag, whatever its name,
+// the others are fallbacks consulted in order for the name, their options are not checked.
+func fieldTag(field reflect.StructField, tagNames []string, f *Field) (tagKind, error) {
+	if len(tagNames) == 0 {
+		return tagNormal, nil
+	}
+
+	own := false
+	if tag, ok := field.Tag.Lookup(tagNames[0]); ok && tagNames[0] != "" {
+		kind, err := parseTag(tag, f)
+		named := tag != "" && !strings.HasPrefix(tag, ",")
+		if err != nil || kind != tagNormal || named {
+			return kind, err
+		}
+		own = true
+	}
+
+	return fallbackTag(field, tagNames[1:], f, own), nil
+}
+
+// fallbackTag takes the name of the field from the first of the tags having it. A tag omitting the field
+// omits it, unless the field has the own tag.
+func fallbackTag(field reflect.StructField, tagNames []string, f *Field, own bool) tagKind {
+	for _, tagName := range tagNames {
+		if tagName == "" {
+			continue
+		}
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		name, omit := parseFallbackTag(tag)
+		if omit && !own {
+			return tagOmit
+		}
+		if name != "" {
+			f.Name = name
+			return tagNormal
+		}
+	}
+
+	return tagNormal
+}
@@ -0,0 +1,311 @@
+// Package textfuncs provides functions converting values from and to text, such as values of url.Values,
+// http.Header, environment variables and default values of fields.
+package textfuncs
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ParseFunc parses the text into the value. The value is settable.
+type ParseFunc func(v reflect.Value, s string) error
+
+// FormatFunc formats the value as text.
+type FormatFunc func(v reflect.Value) (string, error)
+
+// TextFuncs is the storage of functions intended for converting values from and to text.
+// Functions are read without locks, SetParse and SetFormat copy the maps of functions.
+type TextFuncs struct {
+	mu        sync.Mutex                  // Serializes SetParse and SetFormat.
+	parse     map[reflect.Type]ParseFunc  // Initial parse functions, the map is not modified.
+	format    map[reflect.Type]FormatFunc // Initial format functions, the map is not modified.
+	setParse  atomic.Value                // Copy of parse with the functions added by SetParse.
+	setFormat atomic.Value                // Copy of format with the functions added by SetFormat.
+}
+
+// currentParse returns the map of the parse functions.
+func (t *TextFuncs) currentParse() map[reflect.Type]ParseFunc {
+	if parse, ok := t.setParse.Load().(map[reflect.Type]ParseFunc); ok {
+		return parse
+	}
+	return t.parse
+}
+
+// currentFormat returns the map of the format functions.
+func (t *TextFuncs) currentFormat() map[reflect.Type]FormatFunc {
+	if format, ok := t.setFormat.Load().(map[reflect.Type]FormatFunc); ok {
+		return format
+	}
+	return t.format
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// GetParse returns the parse function for the type, if it is not found then nil is returned.
+// Besides registered functions, types implementing encoding.TextUnmarshaler, strings, booleans, numbers,
+// byte slices and pointers to them are supported.
+func (t *TextFuncs) GetParse(typ reflect.Type) ParseFunc {
+	if f := t.currentParse()[typ]; f != nil {
+		return f
+	}
+
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return parseText
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return parseString
+	case reflect.Bool:
+		return parseBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return parseInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return parseUint
+	case reflect.Float32, reflect.Float64:
+		return parseFloat
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return parseBytes
+		}
+	case reflect.Ptr:
+		if elem := t.GetParse(typ.Elem()); elem != nil {
+			return func(v reflect.Value, s string) error {
+				p := reflect.New(typ.Elem())
+				if err := elem(p.Elem(), s); err != nil {
+					return err
+				}
+				v.Set(p)
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetParse sets the parse function for the type.
+func (t *TextFuncs) SetParse(typ reflect.Type, f ParseFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := t.currentParse()
+	m := make(map[reflect.Type]ParseFunc, len(current)+1)
+	for key, fn := range current {
+		m[key] = fn
+	}
+	m[typ] = f
+	t.setParse.Store(m)
+}
+
+// Parse parses the text into the value. The value must be settable.
+func (t *TextFuncs) Parse(v reflect.Value, s string) error {
+	f := t.GetParse(v.Type())
+	if f == nil {
+		return fmt.Errorf("parsing of type «%s» is not supported", v.Type())
+	}
+	return f(v, s)
+}
+
+// GetFormat returns the format function for the type, if it is not found then nil is returned.
+// Besides registered functions, types implementing encoding.TextMarshaler, strings, booleans, numbers,
+// byte slices and pointers to them are supported. Nil pointers are formatted as empty strings.
+func (t *TextFuncs) GetFormat(typ reflect.Type) FormatFunc {
+	if f := t.currentFormat()[typ]; f != nil {
+		return f
+	}
+
+	if typ.Implements(textMarshalerType) {
+		return formatText
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return formatString
+	case reflect.Bool:
+		return formatBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return formatInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return formatUint
+	case reflect.Float32, reflect.Float64:
+		return formatFloat
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return formatBytes
+		}
+	case reflect.Ptr:
+		if elem := t.GetFormat(typ.Elem()); elem != nil {
+			return func(v reflect.Value) (string, error) {
+				if v.IsNil() {
+					return "", nil
+				}
+				return elem(v.Elem())
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetFormat sets the format function for the type.
+func (t *TextFuncs) SetFormat(typ reflect.Type, f FormatFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := t.currentFormat()
+	m := make(map[reflect.Type]FormatFunc, len(current)+1)
+	for key, fn := range current {
+		m[key] = fn
+	}
+	m[typ] = f
+	t.setFormat.Store(m)
+}
+
+// Format formats the value as text.
+func (t *TextFuncs) Format(v reflect.Value) (string, error) {
+	f := t.GetFormat(v.Type())
+	if f == nil {
+		return "", fmt.Errorf("formatting of type «%s» is not supported", v.Type())
+	}
+	return f(v)
+}
+
+func parseText(v reflect.Value, s string) error {
+	return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
+
+func parseString(v reflect.Value, s string) error {
+	v.SetString(s)
+	return nil
+}
+
+func parseBytes(v reflect.Value, s string) error {
+	v.SetBytes([]byte(s))
+	return nil
+}
+
+func parseBool(v reflect.Value, s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	v.SetBool(b)
+	return nil
+}
+
+func parseInt(v reflect.Value, s string) error {
+	i, err := strconv.ParseInt(s, 0, v.Type().Bits())
+	if err != nil {
+		return err
+	}
+	v.SetInt(i)
+	return nil
+}
+
+func parseUint(v reflect.Value, s string) error {
+	i, err := strconv.ParseUint(s, 0, v.Type().Bits())
+	if err != nil {
+		return err
+	}
+	v.SetUint(i)
+	return nil
+}
+
+func parseFloat(v reflect.Value, s string) error {
+	f, err := strconv.ParseFloat(s, v.Type().Bits())
+	if err != nil {
+		return err
+	}
+	v.SetFloat(f)
+	return nil
+}
+
+func parseDuration(v reflect.Value, s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	v.SetInt(int64(d))
+	return nil
+}
+
+func formatText(v reflect.Value) (string, error) {
+	b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	return string(b), err
+}
+
+func formatString(v reflect.Value) (string, error) {
+	return v.String(), nil
+}
+
+func formatBytes(v reflect.Value) (string, error) {
+	return string(v.Bytes()), nil
+}
+
+func formatBool(v reflect.Value) (string, error) {
+	return strconv.FormatBool(v.Bool()), nil
+}
+
+func formatInt(v reflect.Value) (string, error) {
+	return strconv.FormatInt(v.Int(), 10), nil
+}
+
+func formatUint(v reflect.Value) (string, error) {
+	return strconv.FormatUint(v.Uint(), 10), nil
+}
+
+func formatFloat(v reflect.Value) (string, error) {
+	return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+}
+
+func formatDuration(v reflect.Value) (string, error) {
+	return time.Duration(v.Int()).String(), nil
+}
+
+var text = &TextFuncs{
+	parse: map[reflect.Type]ParseFunc{
+		reflect.TypeOf(time.Duration(0)): parseDuration,
+	},
+	format: map[reflect.Type]FormatFunc{
+		reflect.TypeOf(time.Duration(0)): formatDuration,
+	},
+}
+
+// GetParse returns the parse function for the type, if it is not found then nil is returned.
+func GetParse(typ reflect.Type) ParseFunc {
+	return text.GetParse(typ)
+}
+
+// SetParse sets the parse function for the type.
+func SetParse(typ reflect.Type, f ParseFunc) {
+	text.SetParse(typ, f)
+}
+
+// Parse parses the text into the value. The value must be settable.
+func Parse(v reflect.Value, s string) error {
+	return text.Parse(v, s)
+}
+
+// GetFormat returns the format function for the type, if it is not found then nil is returned.
+func GetFormat(typ reflect.Type) FormatFunc {
+	return text.GetFormat(typ)
+}
+
+// SetFormat sets the format function for the type.
+func SetFormat(typ reflect.Type, f FormatFunc) {
+	text.SetFormat(typ, f)
+}
+
+// Format formats the value as text.
+func Format(v reflect.Value) (string, error) {
+	return text.Format(v)
+}
@@ -0,0 +1,127 @@
+package textfuncs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+)
+
+func TestParse(t *testing.T) {
+	type myString string
+
+	for _, test := range []struct {
+		text     string
+		expected interface{}
+	}{
+		{"text", "text"},
+		{"text", myString("text")},
+		{"text", []byte("text")},
+		{"true", true},
+		{"-10", int8(-10)},
+		{"0x10", uint16(16)},
+		{"1.5", float32(1.5)},
+		{"1m30s", 90 * time.Second},
+		{"2021-02-18T16:00:01Z", time.Date(2021, 2, 18, 16, 0, 1, 0, time.UTC)},
+		{"10", ptr.Int(10)},
+	} {
+		v := reflect.New(reflect.TypeOf(test.expected)).Elem()
+		if err := Parse(v, test.text); err != nil {
+			t.Errorf("parse «%s» into «%s»: %s", test.text, v.Type(), err)
+			continue
+		}
+		if !reflect.DeepEqual(v.Interface(), test.expected) {
+			t.Errorf("want «%v» got «%v»", test.expected, v.Interface())
+		}
+	}
+
+	if err := Parse(reflect.New(reflect.TypeOf(int8(0))).Elem(), "1000"); err == nil {
+		t.Error("should fail on out of range value")
+	}
+
+	if err := Parse(reflect.New(reflect.TypeOf(struct{}{})).Elem(), "1000"); err == nil {
+		t.Error("should fail on unsupported type")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	type myString string
+
+	for _, test := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{"text", "text"},
+		{myString("text"), "text"},
+		{[]byte("text"), "text"},
+		{true, "true"},
+		{int8(-10), "-10"},
+		{uint16(16), "16"},
+		{float32(1.5), "1.5"},
+		{90 * time.Second, "1m30s"},
+		{time.Date(2021, 2, 18, 16, 0, 1, 0, time.UTC), "2021-02-18T16:00:01Z"},
+		{ptr.Int(10), "10"},
+		{(*int)(nil), ""},
+	} {
+		s, err := Format(reflect.ValueOf(test.value))
+		if err != nil {
+			t.Errorf("format «%v»: %s", test.value, err)
+			continue
+		}
+		if s != test.expected {
+			t.Errorf("want «%s» got «%s»", test.expected, s)
+		}
+	}
+
+	if _, err := Format(reflect.ValueOf(struct{}{})); err == nil {
+		t.Error("should fail on unsupported type")
+	}
+}
+
+func TestSet(t *testing.T) {
+	type point struct{ X, Y int }
+	typ := reflect.TypeOf(point{})
+
+	// Functions are set while others are read.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = GetParse(typ)
+			_ = GetFormat(typ)
+		}
+	}()
+
+	SetParse(typ, func(v reflect.Value, s string) error {
+		v.Set(reflect.ValueOf(point{X: len(s)}))
+		return nil
+	})
+	SetFormat(typ, func(v reflect.Value) (string, error) {
+		return "point", nil
+	})
+	<-done
+
+	v := reflect.New(typ).Elem()
+	if err := Parse(v, "abc"); err != nil || v.Interface() != (point{X: 3}) {
+		t.Errorf("want «{3 0}» got «%v», error: %v", v.Interface(), err)
+	}
+	if s, err := Format(v); err != nil || s != "point" {
+		t.Errorf("want «point» got «%s», error: %v", s, err)
+	}
+	if GetParse(reflect.TypeOf(0)) == nil {
+		t.Error("parse functions of other types must be kept")
+	}
+}
+
+func BenchmarkGetParseParallel(b *testing.B) {
+	typ := reflect.TypeOf(time.Duration(0))
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if GetParse(typ) == nil {
+				b.Fatal("parse function of duration must be registered")
+			}
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package copy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+
+	"github.com/gotidy/copy/textfuncs"
+)
+
+// FromValues fills dst from the values, such as a parsed query string or form. Dst must be a pointer to struct.
+// Keys are field names, fields of embedded structs are flattened, nested structs are not filled.
+// Values are parsed by the textfuncs package, slice fields take all values of the key, other fields take the first one.
+//
+//   err := c.FromValues(&req, r.URL.Query())
+func (c *Copiers) FromValues(dst interface{}, values url.Values) error {
+	return c.fromText(dst, func(key string) []string { return values[key] })
+}
+
+// FromValues fills dst from the values.
+func FromValues(dst interface{}, values url.Values) error {
+	return defaultCopier.FromValues(dst, values)
+}
+
+// ToValues returns the values of the src fields. Src must be a struct or a pointer to struct.
+// Keys are field names, fields of embedded structs are flattened, nested structs and nil pointers are omitted.
+// Values are formatted by the textfuncs package, slice fields give all values of the key.
+// Policies and omitempty are applied the way ToMap applies them.
+//
+//   values, err := c.ToValues(&req)
+//   u.RawQuery = values.Encode()
+func (c *Copiers) ToValues(src interface{}) (url.Values, error) {
+	values := url.Values{}
+	err := c.toText(src, func(key string, s []string) { values[key] = s })
+	return values, err
+}
+
+// ToValues returns the values of the src fields.
+func ToValues(src interface{}) (url.Values, error) {
+	return defaultCopier.ToValues(src)
+}
+
+// FromHeader fills dst from the header. Dst must be a pointer to struct.
+// Keys are canonicalized field names, otherwise the rules of FromValues are applied.
+//
+//   err := c.FromHeader(&req, r.Header)
+func (c *Copiers) FromHeader(dst interface{}, header http.Header) error {
+	return c.fromText(dst, func(key string) []string { return header[textproto.CanonicalMIMEHeaderKey(key)] })
+}
+
+// FromHeader fills dst from the header.
+func FromHeader(dst interface{}, header http.Header) error {
+	return defaultCopier.FromHeader(dst, header)
+}
+
+// ToHeader returns the header of the src fields. Src must be a struct or a pointer to struct.
+// Keys are canonicalized field names, otherwise the rules of ToValues are applied.
+func (c *Copiers) ToHeader(src interface{}) (http.Header, error) {
+	header := http.Header{}
+	err := c.toText(src, func(key string, s []string) { header[textproto.CanonicalMIMEHeaderKey(key)] = s })
+	return header, err
+}
+
+// ToHeader returns the header of the src fields.
+func ToHeader(src interface{}) (http.Header, error) {
+	return defaultCopier.ToHeader(src)
+}
+
+// textSlice reports whether all values of the key are put into the field of the type.
+func textSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8
+}
+
+func (c *Copiers) fromText(dst interface{}, get func(key string) []string) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		panic("destination must be pointer to struct")
+	}
+	v = v.Elem()
+
+	s := c.cache.GetByType(v.Type())
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if f.Anonymous || f.Options.ReadOnly || c.ignored(f.Name) || c.mapStruct(f.Type) {
+			continue
+		}
+
+		values := get(f.Name)
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := c.parseField(fieldValueAlloc(v, f), values); err != nil {
+			return fmt.Errorf("field «%s»: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// parseField parses the values into the field, slice fields take all values, other fields take the first one.
+func (c *Copiers) parseField(v reflect.Value, values []string) error {
+	elem := v.Type()
+	if textSlice(elem) {
+		elem = elem.Elem()
+	}
+	parse := textfuncs.GetParse(elem)
+	if parse == nil {
+		if c.options.Skip {
+			return nil
+		}
+		return fmt.Errorf("parsing of type «%s» is not supported", elem)
+	}
+
+	if !textSlice(v.Type()) {
+		return parse(v, values[0])
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+	for i, s := range values {
+		if err := parse(slice.Index(i), s); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	v.Set(slice)
+
+	return nil
+}
+
+func (c *Copiers) toText(src interface{}, set func(key string, values []string)) error {
+	v := structValue(src, "source")
+
+	s := c.cache.GetByType(v.Type())
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if f.Anonymous || f.Options.WriteOnly || c.ignored(f.Name) || c.mapStruct(f.Type) {
+			continue
+		}
+
+		elem := f.Type
+		if textSlice(f.Type) {
+			elem = f.Type.Elem()
+		}
+		format := textfuncs.GetFormat(elem)
+		if format == nil {
+			if c.options.Skip {
+				continue
+			}
+			return fmt.Errorf("field «%s»: formatting of type «%s» is not supported", f.Name, elem)
+		}
+
+		fv, ok := c.readValue(context.Background(), v, f)
+		if !ok {
+			continue
+		}
+		if !textSlice(f.Type) {
+			if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				continue
+			}
+			s, err := format(fv)
+			if err != nil {
+				return fmt.Errorf("field «%s»: %w", f.Name, err)
+			}
+			set(f.Name, []string{s})
+			continue
+		}
+
+		if fv.Len() == 0 {
+			continue
+		}
+		values := make([]string, fv.Len())
+		for i := range values {
+			s, err := format(fv.Index(i))
+			if err != nil {
+				return fmt.Errorf("field «%s»: item %d: %w", f.Name, i, err)
+			}
+			values[i] = s
+		}
+		set(f.Name, values)
+	}
+
+	return nil
+}
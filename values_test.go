@@ -0,0 +1,104 @@
+package copy
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+)
+
+type testValuesRequest struct {
+	MapBase
+	Query   string        `copy:"q"`
+	Page    int           `copy:"page"`
+	Limit   *int          `copy:"limit"`
+	Tags    []string      `copy:"tag"`
+	IDs     []int64       `copy:"id"`
+	Timeout time.Duration `copy:"timeout"`
+	Since   time.Time     `copy:"since"`
+	Address testMapAddress
+	Secret  string `copy:"-"`
+}
+
+func TestCopiers_Values(t *testing.T) {
+	values := url.Values{
+		"ID":      {"7"},
+		"q":       {"shoes", "boots"},
+		"page":    {"2"},
+		"limit":   {"50"},
+		"tag":     {"a", "b"},
+		"id":      {"1", "2"},
+		"timeout": {"1m30s"},
+		"since":   {"2021-02-18T16:00:01Z"},
+		"Secret":  {"s"},
+	}
+
+	c := New(Tag("copy"))
+	req := testValuesRequest{}
+	if err := c.FromValues(&req, values); err != nil {
+		t.Fatalf("from values: %s", err)
+	}
+
+	expected := testValuesRequest{
+		MapBase: MapBase{ID: 7},
+		Query:   "shoes",
+		Page:    2,
+		Limit:   ptr.Int(50),
+		Tags:    []string{"a", "b"},
+		IDs:     []int64{1, 2},
+		Timeout: 90 * time.Second,
+		Since:   time.Date(2021, 2, 18, 16, 0, 1, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(req, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, req)
+	}
+
+	back, err := c.ToValues(&req)
+	if err != nil {
+		t.Fatalf("to values: %s", err)
+	}
+	delete(values, "Secret")
+	values["q"] = values["q"][:1]
+	if !reflect.DeepEqual(back, values) {
+		t.Errorf("want «%v» got «%v»", values, back)
+	}
+
+	if err := c.FromValues(&req, url.Values{"page": {"two"}}); err == nil {
+		t.Error("must fail on invalid value")
+	}
+}
+
+func TestCopiers_Header(t *testing.T) {
+	type headers struct {
+		RequestID string   `copy:"x-request-id"`
+		Accept    []string `copy:"accept"`
+		Retries   *int     `copy:"x-retries"`
+	}
+
+	header := http.Header{}
+	header.Set("X-Request-Id", "42")
+	header.Add("Accept", "text/html")
+	header.Add("Accept", "application/json")
+
+	c := New(Tag("copy"))
+	h := headers{}
+	if err := c.FromHeader(&h, header); err != nil {
+		t.Fatalf("from header: %s", err)
+	}
+
+	expected := headers{RequestID: "42", Accept: []string{"text/html", "application/json"}}
+	if !reflect.DeepEqual(h, expected) {
+		t.Errorf("want «%+v» got «%+v»", expected, h)
+	}
+
+	back, err := c.ToHeader(h)
+	if err != nil {
+		t.Fatalf("to header: %s", err)
+	}
+	if !reflect.DeepEqual(back, header) {
+		t.Errorf("want «%v» got «%v»", header, back)
+	}
+}